@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+// generate returns the formatted source of the enum's methods, args are
+// mentioned in the header.
+func generate(e enum, args string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		enum
+		Args string
+	}{e, args})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+var tmpl = template.Must(template.New("enum").Parse(`// Code generated by "enumgen {{.Args}}"; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+var _{{.Type}}Values = []{{.Type}}{
+{{- range .Values}}
+	{{.Name}},
+{{- end}}
+}
+
+var _{{.Type}}Names = map[{{.Type}}]string{
+{{- range .Values}}
+	{{.Name}}: "{{.Name}}",
+{{- end}}
+}
+
+var _{{.Type}}ByName = map[string]{{.Type}}{
+{{- range .Values}}
+	"{{.Name}}": {{.Name}},
+{{- end}}
+}
+
+func _() {
+	// An "invalid array index" or "constant overflows" compiler error
+	// signifies that the constant values have changed.
+	// Re-run enumgen to generate them again.
+	var x [1]struct{}
+{{- range .Values}}
+	_ = x[{{.Name}}-({{.Value}})]
+{{- end}}
+}
+
+// {{.Type}}Values returns all valid {{.Type}} values in declaration order.
+func {{.Type}}Values() []{{.Type}} {
+	return append([]{{.Type}}(nil), _{{.Type}}Values...)
+}
+
+// Parse{{.Type}} returns the {{.Type}} named s, names are case-sensitive.
+func Parse{{.Type}}(s string) ({{.Type}}, error) {
+	if v, ok := _{{.Type}}ByName[s]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("%q is not a valid {{.Type}}", s)
+}
+
+// IsValid reports whether i is one of the declared {{.Type}} constants.
+func (i {{.Type}}) IsValid() bool {
+	_, ok := _{{.Type}}Names[i]
+	return ok
+}
+
+func (i {{.Type}}) String() string {
+	if name, ok := _{{.Type}}Names[i]; ok {
+		return name
+	}
+{{- if .Unsigned}}
+	return "{{.Type}}(" + strconv.FormatUint(uint64(i), 10) + ")"
+{{- else}}
+	return "{{.Type}}(" + strconv.FormatInt(int64(i), 10) + ")"
+{{- end}}
+}
+
+// MarshalText implements encoding.TextMarshaler, invalid values are an error.
+func (i {{.Type}}) MarshalText() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("cannot marshal invalid %s", i)
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *{{.Type}}) UnmarshalText(text []byte) error {
+	v, err := Parse{{.Type}}(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, i is written as its name.
+func (i {{.Type}}) MarshalJSON() ([]byte, error) {
+	text, err := i.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it expects a name.
+func (i *{{.Type}}) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("{{.Type}} should be a string: %w", err)
+	}
+	return i.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer, i is stored as its name.
+func (i {{.Type}}) Value() (driver.Value, error) {
+	text, err := i.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner. It accepts a name as well as a number, so
+// columns holding either work.
+func (i *{{.Type}}) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return i.UnmarshalText([]byte(src))
+	case []byte:
+		return i.UnmarshalText(src)
+	case int64:
+		v := {{.Type}}(src)
+		if int64(v) != src || !v.IsValid() {
+			return fmt.Errorf("%d is not a valid {{.Type}}", src)
+		}
+		*i = v
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into {{.Type}}", src)
+	}
+}
+`))
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pkg is a type-checked package.
+type pkg struct {
+	name  string
+	files []*ast.File
+	info  *types.Info
+	types *types.Package
+}
+
+// enum is what's generated from.
+type enum struct {
+	Package  string
+	Type     string
+	Unsigned bool
+	Values   []value
+}
+
+type value struct {
+	Name  string
+	Value string
+}
+
+// load parses and type-checks the non-test Go files in dir, except the ones
+// enumgen generated, so an outdated one can't get in the way. Imports aren't
+// resolved, the constants of an enum don't depend on them and the errors it
+// causes elsewhere are ignored.
+func load(dir string) (*pkg, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	p := &pkg{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if generatedByUs(f) {
+			continue
+		}
+		if p.name != "" && f.Name.Name != p.name {
+			return nil, fmt.Errorf("%s: found packages %s and %s", dir, p.name, f.Name.Name)
+		}
+		p.name = f.Name.Name
+		p.files = append(p.files, f)
+	}
+	if len(p.files) == 0 {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+
+	p.info = &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	conf := types.Config{
+		Importer: noImporter{},
+		Error:    func(error) {},
+	}
+	p.types, _ = conf.Check(p.name, fset, p.files, p.info)
+	return p, nil
+}
+
+func generatedByUs(f *ast.File) bool {
+	return len(f.Comments) > 0 && f.Comments[0].Pos() < f.Package &&
+		strings.HasPrefix(f.Comments[0].Text(), `Code generated by "enumgen `)
+}
+
+type noImporter struct{}
+
+func (noImporter) Import(path string) (*types.Package, error) {
+	return nil, errors.New("imports aren't resolved")
+}
+
+// enum collects the named constants of typeName in declaration order.
+// Constants with a value that was already seen are aliases, only the first
+// name is used for it.
+func (p *pkg) enum(typeName string) (enum, error) {
+	obj := p.types.Scope().Lookup(typeName)
+	if obj == nil {
+		return enum{}, fmt.Errorf("type %s not found in package %s", typeName, p.name)
+	}
+	typ, ok := obj.(*types.TypeName)
+	if !ok {
+		return enum{}, fmt.Errorf("%s is not a type", typeName)
+	}
+	basic, ok := typ.Type().Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 {
+		return enum{}, fmt.Errorf("%s is not an integer type", typeName)
+	}
+
+	e := enum{
+		Package:  p.name,
+		Type:     typeName,
+		Unsigned: basic.Info()&types.IsUnsigned != 0,
+	}
+	seen := map[string]bool{}
+	for _, f := range p.files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, ident := range spec.(*ast.ValueSpec).Names {
+					c, ok := p.info.Defs[ident].(*types.Const)
+					if !ok || ident.Name == "_" || c.Type() != typ.Type() {
+						continue
+					}
+					v := c.Val().ExactString()
+					if c.Val().Kind() != constant.Int || seen[v] {
+						continue
+					}
+					seen[v] = true
+					e.Values = append(e.Values, value{Name: ident.Name, Value: v})
+				}
+			}
+		}
+	}
+	if len(e.Values) == 0 {
+		return enum{}, fmt.Errorf("no constants of type %s", typeName)
+	}
+	return e, nil
+}
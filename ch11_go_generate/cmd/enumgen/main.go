@@ -0,0 +1,66 @@
+// Command enumgen generates the methods an int enum needs: String, a Parse
+// function, a Values function, IsValid, JSON and text marshalling and the
+// database/sql Scanner and Valuer. It's meant to be run by go generate:
+//
+//	//go:generate go run ./cmd/enumgen -type=Direction
+//
+// Every named constant of the type becomes a value of the enum, its name is
+// the string form. Blank constants, usually the zero value declared as
+// `_ Direction = iota`, aren't values, so the zero value is invalid unless a
+// named constant has it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("enumgen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of type names, required")
+	output := flag.String("output", "", "output file name, <type>_enum.go by default")
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *output != "" && strings.Contains(*typeNames, ",") {
+		log.Fatal("-output can only be used with a single type")
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	pkg, err := load(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, typeName := range strings.Split(*typeNames, ",") {
+		enum, err := pkg.enum(typeName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		src, err := generate(enum, strings.Join(os.Args[1:], " "))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		name := *output
+		if name == "" {
+			name = strings.ToLower(typeName) + "_enum.go"
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), src, 0o644); err != nil {
+			log.Fatal(fmt.Errorf("writing %s: %w", name, err))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnum(t *testing.T) {
+	p, err := load("testdata/colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		typeName string
+		want     enum
+	}{
+		{"Color", enum{Package: "colors", Type: "Color", Unsigned: true, Values: []value{
+			{"Red", "1"}, {"Green", "2"}, {"Blue", "3"}, {"Purple", "10"},
+		}}},
+		{"Size", enum{Package: "colors", Type: "Size", Values: []value{
+			{"Small", "-1"}, {"Medium", "0"}, {"Large", "1"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			got, err := p.enum(tt.typeName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSkipsGeneratedFiles(t *testing.T) {
+	// shape_enum.go was generated before the package was renamed and
+	// Triangle was removed, taking it into account would fail
+	p, err := load("testdata/stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.enum("Shape")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := enum{Package: "shapes", Type: "Shape", Values: []value{{"Circle", "0"}, {"Square", "1"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEnumErrors(t *testing.T) {
+	p, err := load("testdata/colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typeName := range []string{"Missing", "Palette"} {
+		if _, err := p.enum(typeName); err == nil {
+			t.Errorf("enum(%q) didn't fail", typeName)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	p, err := load("testdata/colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := p.enum("Color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(e, "-type=Color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`// Code generated by "enumgen -type=Color"; DO NOT EDIT.`,
+		"func ParseColor(s string) (Color, error)",
+		"func ColorValues() []Color",
+		"strconv.FormatUint(uint64(i), 10)",
+		`Purple: "Purple",`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(string(src), "Crimson") {
+		t.Error("alias Crimson was generated")
+	}
+}
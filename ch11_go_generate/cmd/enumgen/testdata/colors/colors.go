@@ -0,0 +1,25 @@
+package colors
+
+import "image/color"
+
+type Color uint8
+
+const (
+	_ Color = iota
+	Red
+	Green
+	Blue
+	Crimson = Red
+)
+
+const Purple Color = 10
+
+type Size int
+
+const (
+	Small Size = iota - 1
+	Medium
+	Large
+)
+
+var Palette = color.Palette{}
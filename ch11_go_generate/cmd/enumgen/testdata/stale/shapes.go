@@ -0,0 +1,8 @@
+package shapes
+
+type Shape int
+
+const (
+	Circle Shape = iota
+	Square
+)
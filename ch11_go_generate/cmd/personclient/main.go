@@ -0,0 +1,182 @@
+// Command personclient talks to a personserver.
+//
+//	personclient create -name "Bob Bobson" -email bob@bobson.com -tag admin
+//	personclient get -id 1
+//	personclient get -email bob@bobson.com
+//	personclient list [-tag admin]
+//	personclient delete -id 1
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"ch11_go_generate/data"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const usage = `usage: personclient [-addr host:port] command [flags]
+
+commands:
+  create -name s [-id n] [-email s] [-tag s]...
+  get -id n | -email s
+  list [-tag s]
+  delete -id n
+
+flags:
+`
+
+func main() {
+	log.SetFlags(0)
+	addr := flag.String("addr", "localhost:50051", "address of the personserver")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout for the call")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	client := data.NewPersonServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "create":
+		err = create(ctx, client, args)
+	case "get":
+		err = get(ctx, client, args)
+	case "list":
+		err = list(ctx, client, args)
+	case "delete":
+		err = remove(ctx, client, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		cancel()
+		log.Fatal(err)
+	}
+}
+
+// repeated collects a flag that can be given more than once
+type repeated []string
+
+func (r *repeated) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *repeated) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+func create(ctx context.Context, client data.PersonServiceClient, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	id := fs.Int("id", 0, "id, the next free one if 0")
+	name := fs.String("name", "", "name")
+	email := fs.String("email", "", "email")
+	var tags repeated
+	fs.Var(&tags, "tag", "tag, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := client.Create(ctx, &data.CreatePersonRequest{Person: &data.Person{
+		Id: int32(*id), Name: *name, Email: *email, Tags: tags,
+	}})
+	if err != nil {
+		return err
+	}
+	return printPerson(p)
+}
+
+func get(ctx context.Context, client data.PersonServiceClient, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	id := fs.Int("id", 0, "id to look up")
+	email := fs.String("email", "", "email to look up")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req := &data.GetPersonRequest{}
+	switch {
+	case *id != 0:
+		req.Key = &data.GetPersonRequest_Id{Id: int32(*id)}
+	case *email != "":
+		req.Key = &data.GetPersonRequest_Email{Email: *email}
+	default:
+		return errors.New("get needs -id or -email")
+	}
+	p, err := client.Get(ctx, req)
+	if err != nil {
+		return err
+	}
+	return printPerson(p)
+}
+
+func list(ctx context.Context, client data.PersonServiceClient, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	tag := fs.String("tag", "", "only list people with this tag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	stream, err := client.List(ctx, &data.ListPeopleRequest{Tag: *tag})
+	if err != nil {
+		return err
+	}
+	for {
+		p, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d\t%s\t%s\t%s\n", p.GetId(), p.GetName(), p.GetEmail(), strings.Join(p.GetTags(), ","))
+	}
+}
+
+func remove(ctx context.Context, client data.PersonServiceClient, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	id := fs.Int("id", 0, "id of the person to delete")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return errors.New("delete needs -id")
+	}
+	_, err := client.Delete(ctx, &data.DeletePersonRequest{Id: int32(*id)})
+	return err
+}
+
+func printPerson(p *data.Person) error {
+	b, err := protojson.MarshalOptions{Multiline: true}.Marshal(p)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
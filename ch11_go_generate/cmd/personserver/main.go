@@ -0,0 +1,47 @@
+// Command personserver serves the PersonService, keeping the people in a
+// registry file or, without -file, in memory.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+
+	"ch11_go_generate/data"
+	"ch11_go_generate/personsvc"
+	"ch11_go_generate/registry"
+
+	"google.golang.org/grpc"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "address to listen on")
+	file := flag.String("file", "", "registry file, people are kept in memory if empty")
+	flag.Parse()
+
+	var store personsvc.Store = registry.NewMemory()
+	if *file != "" {
+		store = registry.Open(*file)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	data.RegisterPersonServiceServer(srv, personsvc.NewServer(store))
+
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		srv.GracefulStop()
+	}()
+
+	log.Printf("Serving PersonService on %s", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,191 @@
+// Command registry keeps people in a file of length-delimited protobuf
+// records.
+//
+//	registry [-file people.pb] add -id 20 -name "Bob Bobson" -email bob@bobson.com -phone work:+431234 -tag admin
+//	registry list
+//	registry get -id 20
+//	registry get -email bob@bobson.com
+//	registry export > people.json
+//	registry import people.json
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"ch11_go_generate/data"
+	"ch11_go_generate/registry"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const usage = `usage: registry [-file path] command [flags]
+
+commands:
+  add -id n -name s [-email s] [-phone type:number]... [-tag s]...
+  list
+  get -id n | -email s
+  export
+  import file.json
+
+flags:
+`
+
+func main() {
+	log.SetFlags(0)
+	file := flag.String("file", "people.pb", "registry file")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	store := registry.Open(*file)
+	args := flag.Args()[1:]
+	var err error
+	switch flag.Arg(0) {
+	case "add":
+		err = add(store, args)
+	case "list":
+		err = list(store)
+	case "get":
+		err = get(store, args)
+	case "export":
+		err = export(store)
+	case "import":
+		err = importJSON(store, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// repeated collects a flag that can be given more than once
+type repeated []string
+
+func (r *repeated) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *repeated) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+func add(store *registry.Store, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	id := fs.Int("id", 0, "id, must be unique")
+	name := fs.String("name", "", "name")
+	email := fs.String("email", "", "email, must be unique")
+	var phones, tags repeated
+	fs.Var(&phones, "phone", "phone as type:number, type is mobile, home or work, can be repeated")
+	fs.Var(&tags, "tag", "tag, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 || *name == "" {
+		return errors.New("add needs -id and -name")
+	}
+
+	p := &data.Person{Id: int32(*id), Name: *name, Email: *email, Tags: tags}
+	for _, phone := range phones {
+		number, err := parsePhone(phone)
+		if err != nil {
+			return err
+		}
+		p.Phones = append(p.Phones, number)
+	}
+	return store.Append(p)
+}
+
+func parsePhone(s string) (*data.Person_PhoneNumber, error) {
+	kind, number, ok := strings.Cut(s, ":")
+	if !ok {
+		return &data.Person_PhoneNumber{Number: s}, nil
+	}
+	t, ok := data.Person_PhoneType_value[strings.ToUpper(kind)]
+	if !ok {
+		return nil, fmt.Errorf("unknown phone type %s", kind)
+	}
+	return &data.Person_PhoneNumber{Number: number, Type: data.Person_PhoneType(t)}, nil
+}
+
+func list(store *registry.Store) error {
+	people, err := store.List()
+	for _, p := range people {
+		fmt.Printf("%d\t%s\t%s\t%s\n", p.GetId(), p.GetName(), p.GetEmail(), strings.Join(p.GetTags(), ","))
+	}
+	return err
+}
+
+func get(store *registry.Store, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	id := fs.Int("id", 0, "id to look up")
+	email := fs.String("email", "", "email to look up")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var p *data.Person
+	var err error
+	switch {
+	case *id != 0:
+		p, err = store.ByID(int32(*id))
+	case *email != "":
+		p, err = store.ByEmail(*email)
+	default:
+		return errors.New("get needs -id or -email")
+	}
+	if err != nil {
+		return err
+	}
+	b, err := protojson.MarshalOptions{Multiline: true}.Marshal(p)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func export(store *registry.Store) error {
+	people, err := store.List()
+	if err != nil {
+		return err
+	}
+	b, err := registry.MarshalJSON(people)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func importJSON(store *registry.Store, args []string) error {
+	if len(args) != 1 {
+		return errors.New("import needs a file")
+	}
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	people, err := registry.UnmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	if err := store.Append(people...); err != nil {
+		return err
+	}
+	log.Printf("Imported %d people", len(people))
+	return nil
+}
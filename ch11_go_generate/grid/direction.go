@@ -0,0 +1,90 @@
+// Package grid has the pieces robot-simulation puzzles keep needing:
+// directions that can be turned, points that can be moved and rune grids
+// loaded from text that can be searched for shortest paths.
+//
+// Y grows downwards like the lines of a text, so North is -Y.
+package grid
+
+// Direction is one of the eight compass directions. The zero value isn't a
+// valid direction.
+type Direction int
+
+// Scan accepts the values from integer columns, so they must not change.
+// The four cardinal directions keep the values they had before the diagonal
+// ones were added after them.
+const (
+	_ Direction = iota
+	North
+	South
+	East
+	West
+	NorthEast
+	SouthEast
+	SouthWest
+	NorthWest
+)
+
+//go:generate go run ../cmd/enumgen -type=Direction
+
+// Cardinal are the four directions that aren't diagonal, clockwise from
+// North. DirectionValues returns all eight.
+var Cardinal = []Direction{North, East, South, West}
+
+// clockwise are all directions in clockwise order, starting at North.
+var clockwise = [...]Direction{North, NorthEast, East, SouthEast, South, SouthWest, West, NorthWest}
+
+// Rotate turns d clockwise by steps of 45 degrees, counterclockwise if steps
+// is negative. Invalid directions stay as they are.
+func (d Direction) Rotate(steps int) Direction {
+	for i, c := range clockwise {
+		if c == d {
+			return clockwise[((i+steps)%8+8)%8]
+		}
+	}
+	return d
+}
+
+// Opposite returns the direction pointing the other way.
+func (d Direction) Opposite() Direction {
+	return d.Rotate(4)
+}
+
+// TurnRight turns d clockwise by 90 degrees.
+func (d Direction) TurnRight() Direction {
+	return d.Rotate(2)
+}
+
+// TurnLeft turns d counterclockwise by 90 degrees.
+func (d Direction) TurnLeft() Direction {
+	return d.Rotate(-2)
+}
+
+// IsDiagonal reports whether d is one of NorthEast, SouthEast, SouthWest
+// and NorthWest.
+func (d Direction) IsDiagonal() bool {
+	return d.IsValid() && d >= NorthEast
+}
+
+// Delta is the point one step in direction d from the origin, the zero
+// point for invalid directions.
+func (d Direction) Delta() Point {
+	switch d {
+	case North:
+		return Point{0, -1}
+	case NorthEast:
+		return Point{1, -1}
+	case East:
+		return Point{1, 0}
+	case SouthEast:
+		return Point{1, 1}
+	case South:
+		return Point{0, 1}
+	case SouthWest:
+		return Point{-1, 1}
+	case West:
+		return Point{-1, 0}
+	case NorthWest:
+		return Point{-1, -1}
+	}
+	return Point{}
+}
@@ -0,0 +1,149 @@
+// Code generated by "enumgen -type=Direction"; DO NOT EDIT.
+
+package grid
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+var _DirectionValues = []Direction{
+	North,
+	South,
+	East,
+	West,
+	NorthEast,
+	SouthEast,
+	SouthWest,
+	NorthWest,
+}
+
+var _DirectionNames = map[Direction]string{
+	North:     "North",
+	South:     "South",
+	East:      "East",
+	West:      "West",
+	NorthEast: "NorthEast",
+	SouthEast: "SouthEast",
+	SouthWest: "SouthWest",
+	NorthWest: "NorthWest",
+}
+
+var _DirectionByName = map[string]Direction{
+	"North":     North,
+	"South":     South,
+	"East":      East,
+	"West":      West,
+	"NorthEast": NorthEast,
+	"SouthEast": SouthEast,
+	"SouthWest": SouthWest,
+	"NorthWest": NorthWest,
+}
+
+func _() {
+	// An "invalid array index" or "constant overflows" compiler error
+	// signifies that the constant values have changed.
+	// Re-run enumgen to generate them again.
+	var x [1]struct{}
+	_ = x[North-(1)]
+	_ = x[South-(2)]
+	_ = x[East-(3)]
+	_ = x[West-(4)]
+	_ = x[NorthEast-(5)]
+	_ = x[SouthEast-(6)]
+	_ = x[SouthWest-(7)]
+	_ = x[NorthWest-(8)]
+}
+
+// DirectionValues returns all valid Direction values in declaration order.
+func DirectionValues() []Direction {
+	return append([]Direction(nil), _DirectionValues...)
+}
+
+// ParseDirection returns the Direction named s, names are case-sensitive.
+func ParseDirection(s string) (Direction, error) {
+	if v, ok := _DirectionByName[s]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("%q is not a valid Direction", s)
+}
+
+// IsValid reports whether i is one of the declared Direction constants.
+func (i Direction) IsValid() bool {
+	_, ok := _DirectionNames[i]
+	return ok
+}
+
+func (i Direction) String() string {
+	if name, ok := _DirectionNames[i]; ok {
+		return name
+	}
+	return "Direction(" + strconv.FormatInt(int64(i), 10) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler, invalid values are an error.
+func (i Direction) MarshalText() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, fmt.Errorf("cannot marshal invalid %s", i)
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *Direction) UnmarshalText(text []byte) error {
+	v, err := ParseDirection(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, i is written as its name.
+func (i Direction) MarshalJSON() ([]byte, error) {
+	text, err := i.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it expects a name.
+func (i *Direction) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Direction should be a string: %w", err)
+	}
+	return i.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer, i is stored as its name.
+func (i Direction) Value() (driver.Value, error) {
+	text, err := i.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner. It accepts a name as well as a number, so
+// columns holding either work.
+func (i *Direction) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return i.UnmarshalText([]byte(src))
+	case []byte:
+		return i.UnmarshalText(src)
+	case int64:
+		v := Direction(src)
+		if int64(v) != src || !v.IsValid() {
+			return fmt.Errorf("%d is not a valid Direction", src)
+		}
+		*i = v
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Direction", src)
+	}
+}
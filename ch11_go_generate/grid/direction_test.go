@@ -0,0 +1,157 @@
+package grid
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDirectionParse(t *testing.T) {
+	for _, d := range DirectionValues() {
+		got, err := ParseDirection(d.String())
+		if err != nil || got != d {
+			t.Errorf("ParseDirection(%q) = %v, %v", d.String(), got, err)
+		}
+	}
+	for _, s := range []string{"", "_", "north", "Up"} {
+		if _, err := ParseDirection(s); err == nil {
+			t.Errorf("ParseDirection(%q) didn't fail", s)
+		}
+	}
+}
+
+func TestDirectionIsValid(t *testing.T) {
+	tests := []struct {
+		d    Direction
+		want bool
+	}{
+		{0, false},
+		{North, true},
+		{West, true},
+		{NorthWest + 1, false},
+		{-1, false},
+	}
+	for _, tt := range tests {
+		if got := tt.d.IsValid(); got != tt.want {
+			t.Errorf("%v.IsValid() = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+	if got := Direction(0).String(); got != "Direction(0)" {
+		t.Errorf("zero value String() = %q", got)
+	}
+}
+
+func TestDirectionJSON(t *testing.T) {
+	type heading struct {
+		Dir Direction `json:"dir"`
+	}
+	data, err := json.Marshal(heading{South})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"dir":"South"}` {
+		t.Errorf("got %s", data)
+	}
+	var h heading
+	if err := json.Unmarshal(data, &h); err != nil || h.Dir != South {
+		t.Errorf("Unmarshal = %v, %v", h.Dir, err)
+	}
+
+	if _, err := json.Marshal(heading{}); err == nil {
+		t.Error("marshalling the zero value didn't fail")
+	}
+	for _, in := range []string{`{"dir":"Up"}`, `{"dir":2}`} {
+		if err := json.Unmarshal([]byte(in), &h); err == nil {
+			t.Errorf("Unmarshal(%s) didn't fail", in)
+		}
+	}
+
+	var m map[Direction]int
+	if err := json.Unmarshal([]byte(`{"East":1}`), &m); err != nil || !reflect.DeepEqual(m, map[Direction]int{East: 1}) {
+		t.Errorf("map keys: %v, %v", m, err)
+	}
+}
+
+func TestDirectionSQL(t *testing.T) {
+	v, err := East.Value()
+	if err != nil || v != "East" {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if _, err := Direction(0).Value(); err == nil {
+		t.Error("Value() of the zero value didn't fail")
+	}
+
+	tests := []struct {
+		src     any
+		want    Direction
+		wantErr bool
+	}{
+		{"North", North, false},
+		{[]byte("West"), West, false},
+		{int64(2), South, false},
+		{int64(3), East, false},
+		{int64(5), NorthEast, false},
+		{int64(0), 0, true},
+		{int64(1 << 40), 0, true},
+		{"Up", 0, true},
+		{3.0, 0, true},
+	}
+	for _, tt := range tests {
+		var d Direction
+		err := d.Scan(tt.src)
+		if (err != nil) != tt.wantErr || d != tt.want {
+			t.Errorf("Scan(%v) = %v, %v", tt.src, d, err)
+		}
+	}
+}
+
+func TestDirectionValuesAreStable(t *testing.T) {
+	// Scan reads them from integer columns, see the const block
+	want := map[Direction]int{
+		North: 1, South: 2, East: 3, West: 4,
+		NorthEast: 5, SouthEast: 6, SouthWest: 7, NorthWest: 8,
+	}
+	for d, v := range want {
+		if int(d) != v {
+			t.Errorf("%v = %d, want %d", d, int(d), v)
+		}
+	}
+}
+
+func TestDirectionTurns(t *testing.T) {
+	tests := []struct {
+		d                           Direction
+		opposite, left, right, cw45 Direction
+	}{
+		{North, South, West, East, NorthEast},
+		{East, West, North, South, SouthEast},
+		{SouthWest, NorthEast, SouthEast, NorthWest, West},
+		{NorthWest, SouthEast, SouthWest, NorthEast, North},
+		{0, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.d.Opposite(); got != tt.opposite {
+			t.Errorf("%v.Opposite() = %v, want %v", tt.d, got, tt.opposite)
+		}
+		if got := tt.d.TurnLeft(); got != tt.left {
+			t.Errorf("%v.TurnLeft() = %v, want %v", tt.d, got, tt.left)
+		}
+		if got := tt.d.TurnRight(); got != tt.right {
+			t.Errorf("%v.TurnRight() = %v, want %v", tt.d, got, tt.right)
+		}
+		if got := tt.d.Rotate(1); got != tt.cw45 {
+			t.Errorf("%v.Rotate(1) = %v, want %v", tt.d, got, tt.cw45)
+		}
+	}
+	for _, d := range DirectionValues() {
+		if got := d.Rotate(-17); got != d.Rotate(-1) {
+			t.Errorf("%v.Rotate(-17) = %v, want %v", d, got, d.Rotate(-1))
+		}
+		if d.Delta().Add(d.Opposite().Delta()) != (Point{}) {
+			t.Errorf("%v and its opposite don't cancel out", d)
+		}
+		if got, want := d.IsDiagonal(), d.Delta().X != 0 && d.Delta().Y != 0; got != want {
+			t.Errorf("%v.IsDiagonal() = %v", d, got)
+		}
+	}
+}
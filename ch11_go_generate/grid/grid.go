@@ -0,0 +1,102 @@
+package grid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Grid is a rectangle of runes, like a map in a puzzle input.
+type Grid struct {
+	cells  [][]rune
+	width  int
+	height int
+}
+
+// New returns a width by height grid filled with fill.
+func New(width, height int, fill rune) *Grid {
+	g := &Grid{width: width, height: height, cells: make([][]rune, height)}
+	for y := range g.cells {
+		g.cells[y] = []rune(strings.Repeat(string(fill), width))
+	}
+	return g
+}
+
+// Parse reads a grid, one row per line. All rows must have the same number
+// of runes, a trailing empty line is ignored.
+func Parse(r io.Reader) (*Grid, error) {
+	g := &Grid{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		row := []rune(strings.TrimSuffix(scanner.Text(), "\r"))
+		if g.height == 0 {
+			g.width = len(row)
+		} else if len(row) != g.width {
+			return nil, fmt.Errorf("line %d has %d runes, expected %d", g.height+1, len(row), g.width)
+		}
+		g.cells = append(g.cells, row)
+		g.height++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ParseString is Parse for grids that are already in memory.
+func ParseString(s string) (*Grid, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// Width is the number of columns.
+func (g *Grid) Width() int {
+	return g.width
+}
+
+// Height is the number of rows.
+func (g *Grid) Height() int {
+	return g.height
+}
+
+// In reports whether p lies on the grid.
+func (g *Grid) In(p Point) bool {
+	return p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height
+}
+
+// At returns the rune at p, or 0 if p isn't on the grid.
+func (g *Grid) At(p Point) rune {
+	if !g.In(p) {
+		return 0
+	}
+	return g.cells[p.Y][p.X]
+}
+
+// Set replaces the rune at p, points off the grid are ignored.
+func (g *Grid) Set(p Point, r rune) {
+	if g.In(p) {
+		g.cells[p.Y][p.X] = r
+	}
+}
+
+// Find returns the first point holding r, scanning row by row.
+func (g *Grid) Find(r rune) (Point, bool) {
+	for y, row := range g.cells {
+		for x, c := range row {
+			if c == r {
+				return Point{x, y}, true
+			}
+		}
+	}
+	return Point{}, false
+}
+
+// String returns the grid in the format Parse reads.
+func (g *Grid) String() string {
+	var sb strings.Builder
+	for _, row := range g.cells {
+		sb.WriteString(string(row))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,157 @@
+package grid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPoint(t *testing.T) {
+	p := Point{2, 3}
+	if got := p.Move(North, 3); got != (Point{2, 0}) {
+		t.Errorf("Move(North, 3) = %v", got)
+	}
+	if got := p.Move(SouthWest, 2); got != (Point{0, 5}) {
+		t.Errorf("Move(SouthWest, 2) = %v", got)
+	}
+	if got := p.Move(East, -1); got != (Point{1, 3}) {
+		t.Errorf("Move(East, -1) = %v", got)
+	}
+
+	q := Point{-1, 7}
+	if got := p.Manhattan(q); got != 7 {
+		t.Errorf("Manhattan = %d, want 7", got)
+	}
+	if got := p.Chebyshev(q); got != 4 {
+		t.Errorf("Chebyshev = %d, want 4", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	g, err := ParseString("ab\r\ncd\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width() != 2 || g.Height() != 2 {
+		t.Errorf("size = %dx%d, want 2x2", g.Width(), g.Height())
+	}
+	if got := g.At(Point{1, 1}); got != 'd' {
+		t.Errorf("At(1,1) = %q", got)
+	}
+	if got := g.At(Point{2, 0}); got != 0 {
+		t.Errorf("At off the grid = %q", got)
+	}
+	g.Set(Point{0, 1}, 'x')
+	if got := g.String(); got != "ab\nxd\n" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, err := ParseString("abc\nde\n"); err == nil {
+		t.Error("ragged grid didn't fail")
+	}
+}
+
+const maze = `S.#.....
+.##.###.
+....#...
+.####.#.
+......#E
+`
+
+func TestBFS(t *testing.T) {
+	g, err := ParseString(maze)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, _ := g.Find('S')
+	end, _ := g.Find('E')
+
+	tests := []struct {
+		name  string
+		opts  []SearchOption
+		steps int
+	}{
+		{"cardinal", nil, 15},
+		{"diagonal", []SearchOption{WithDirections(DirectionValues()...)}, 11},
+		{"walls are passable", []SearchOption{WithPassable(func(rune) bool { return true })}, 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := g.BFS(start, end, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(path)-1 != tt.steps {
+				t.Errorf("got %d steps, want %d: %v", len(path)-1, tt.steps, path)
+			}
+			checkPath(t, path, start, end)
+		})
+	}
+
+	g.Set(Point{0, 1}, '#')
+	g.Set(Point{1, 0}, '#')
+	if _, err := g.BFS(start, end); !errors.Is(err, ErrNoPath) {
+		t.Errorf("walled in start: err = %v, want ErrNoPath", err)
+	}
+	if _, err := g.BFS(start, Point{-1, 0}); !errors.Is(err, ErrNoPath) {
+		t.Errorf("target off the grid: err = %v, want ErrNoPath", err)
+	}
+}
+
+func TestAStar(t *testing.T) {
+	g, err := ParseString(maze)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, _ := g.Find('S')
+	end, _ := g.Find('E')
+
+	// AStar with unit costs finds paths as short as BFS does
+	for _, dirs := range [][]Direction{Cardinal, DirectionValues()} {
+		bfs, err := g.BFS(start, end, WithDirections(dirs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, cost, err := g.AStar(start, end, WithDirections(dirs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost != len(bfs)-1 || len(path) != len(bfs) {
+			t.Errorf("cost %d, %d points, BFS took %d steps", cost, len(path), len(bfs)-1)
+		}
+		checkPath(t, path, start, end)
+	}
+
+	// walking through mud costs 10, going around is cheaper
+	g, err = ParseString("S~E\n...\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mud := WithCost(func(from, to Point) int {
+		if g.At(to) == '~' {
+			return 10
+		}
+		return 1
+	})
+	path, cost, err := g.AStar(Point{0, 0}, Point{2, 0}, mud)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Point{{0, 0}, {0, 1}, {1, 1}, {2, 1}, {2, 0}}
+	if cost != 4 || !reflect.DeepEqual(path, want) {
+		t.Errorf("got %v with cost %d, want %v with cost 4", path, cost, want)
+	}
+}
+
+// checkPath fails t unless path leads from start to end in single steps.
+func checkPath(t *testing.T, path []Point, start, end Point) {
+	t.Helper()
+	if path[0] != start || path[len(path)-1] != end {
+		t.Errorf("path %v doesn't lead from %v to %v", path, start, end)
+	}
+	for i := 1; i < len(path); i++ {
+		if path[i-1].Chebyshev(path[i]) != 1 {
+			t.Errorf("%v to %v isn't a single step", path[i-1], path[i])
+		}
+	}
+}
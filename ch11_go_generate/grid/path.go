@@ -0,0 +1,161 @@
+package grid
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// ErrNoPath is returned when the target can't be reached.
+var ErrNoPath = errors.New("no path")
+
+type search struct {
+	dirs     []Direction
+	passable func(rune) bool
+	cost     func(from, to Point) int
+}
+
+// SearchOption configures BFS and AStar.
+type SearchOption func(*search)
+
+// WithDirections sets the directions a step may go in, Cardinal by
+// default.
+func WithDirections(dirs ...Direction) SearchOption {
+	return func(s *search) {
+		s.dirs = dirs
+	}
+}
+
+// WithPassable sets which runes can be stepped on, everything but '#' by
+// default. Points off the grid are never passable.
+func WithPassable(passable func(rune) bool) SearchOption {
+	return func(s *search) {
+		s.passable = passable
+	}
+}
+
+// WithCost sets the cost of the step between two neighbouring points, it's
+// 1 by default and must be at least 1. BFS ignores it.
+func WithCost(cost func(from, to Point) int) SearchOption {
+	return func(s *search) {
+		s.cost = cost
+	}
+}
+
+func newSearch(opts []SearchOption) search {
+	s := search{
+		dirs:     Cardinal,
+		passable: func(r rune) bool { return r != '#' },
+		cost:     func(from, to Point) int { return 1 },
+	}
+	for _, o := range opts {
+		o(&s)
+	}
+	return s
+}
+
+func (g *Grid) next(s search, p Point) []Point {
+	var out []Point
+	for _, n := range p.Neighbors(s.dirs) {
+		if g.In(n) && s.passable(g.At(n)) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// BFS returns a path with the fewest steps from from to to, both included.
+func (g *Grid) BFS(from, to Point, opts ...SearchOption) ([]Point, error) {
+	s := newSearch(opts)
+	if !g.In(from) || !g.In(to) {
+		return nil, ErrNoPath
+	}
+
+	prev := map[Point]Point{from: from}
+	queue := []Point{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p == to {
+			return walkBack(prev, from, to), nil
+		}
+		for _, n := range g.next(s, p) {
+			if _, seen := prev[n]; !seen {
+				prev[n] = p
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil, ErrNoPath
+}
+
+// AStar returns the cheapest path from from to to, both included, and its
+// cost. The heuristic is the Manhattan distance, or the Chebyshev distance
+// if diagonal steps are allowed.
+func (g *Grid) AStar(from, to Point, opts ...SearchOption) ([]Point, int, error) {
+	s := newSearch(opts)
+	if !g.In(from) || !g.In(to) {
+		return nil, 0, ErrNoPath
+	}
+
+	estimate := to.Manhattan
+	for _, d := range s.dirs {
+		if d.IsDiagonal() {
+			estimate = to.Chebyshev
+		}
+	}
+
+	prev := map[Point]Point{from: from}
+	cost := map[Point]int{from: 0}
+	open := &frontier{{p: from, priority: estimate(from)}}
+	for open.Len() > 0 {
+		item := heap.Pop(open).(node)
+		if item.p == to {
+			return walkBack(prev, from, to), cost[to], nil
+		}
+		if item.priority-estimate(item.p) > cost[item.p] {
+			// a cheaper way to item.p was found after it was queued
+			continue
+		}
+		for _, n := range g.next(s, item.p) {
+			c := cost[item.p] + s.cost(item.p, n)
+			if old, seen := cost[n]; seen && old <= c {
+				continue
+			}
+			cost[n] = c
+			prev[n] = item.p
+			heap.Push(open, node{p: n, priority: c + estimate(n)})
+		}
+	}
+	return nil, 0, ErrNoPath
+}
+
+func walkBack(prev map[Point]Point, from, to Point) []Point {
+	path := []Point{to}
+	for p := to; p != from; {
+		p = prev[p]
+		path = append(path, p)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type node struct {
+	p        Point
+	priority int
+}
+
+// frontier is a min-heap of nodes by priority.
+type frontier []node
+
+func (f frontier) Len() int           { return len(f) }
+func (f frontier) Less(i, j int) bool { return f[i].priority < f[j].priority }
+func (f frontier) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f *frontier) Push(x any)        { *f = append(*f, x.(node)) }
+func (f *frontier) Pop() any {
+	old := *f
+	n := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return n
+}
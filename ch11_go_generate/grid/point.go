@@ -0,0 +1,51 @@
+package grid
+
+import "fmt"
+
+// Point is a position on a grid.
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+// Add returns the sum of p and q.
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+// Move returns the point n steps in direction d from p.
+func (p Point) Move(d Direction, n int) Point {
+	delta := d.Delta()
+	return Point{p.X + n*delta.X, p.Y + n*delta.Y}
+}
+
+// Manhattan is the number of steps from p to q when moving only in the
+// Cardinal directions.
+func (p Point) Manhattan(q Point) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y)
+}
+
+// Chebyshev is the number of steps from p to q when moving diagonally is
+// allowed too.
+func (p Point) Chebyshev(q Point) int {
+	return max(abs(p.X-q.X), abs(p.Y-q.Y))
+}
+
+// Neighbors returns the points one step from p in each of dirs.
+func (p Point) Neighbors(dirs []Direction) []Point {
+	out := make([]Point, 0, len(dirs))
+	for _, d := range dirs {
+		out = append(out, p.Move(d, 1))
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
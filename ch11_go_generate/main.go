@@ -2,23 +2,13 @@ package main
 
 import (
 	"ch11_go_generate/data"
+	"ch11_go_generate/grid"
+	"encoding/json"
 	"fmt"
 	"google.golang.org/protobuf/proto"
 )
 
-//go:generate protoc -I=. --go_out=. --go_opt=module=ch11_go_generate --go_opt=Mperson.proto=ch11_go_generate/data person.proto
-
-type Direction int
-
-const (
-	_ Direction = iota
-	North
-	South
-	East
-	West
-)
-
-//go:generate stringer -type=Direction
+//go:generate protoc -I=. --go_out=. --go_opt=module=ch11_go_generate --go_opt=Mperson.proto=ch11_go_generate/data --go_opt=Mperson_service.proto=ch11_go_generate/data --go-grpc_out=. --go-grpc_opt=module=ch11_go_generate --go-grpc_opt=Mperson.proto=ch11_go_generate/data --go-grpc_opt=Mperson_service.proto=ch11_go_generate/data person.proto person_service.proto
 
 func main() {
 	// proto
@@ -31,6 +21,18 @@ func main() {
 	protoBytes, _ := proto.Marshal(p)
 	fmt.Println(protoBytes)
 
-	// stringer
-	fmt.Println(North.String())
+	// enumgen
+	fmt.Println(grid.North.String())
+	d, err := grid.ParseDirection("West")
+	fmt.Println(d, err, d.IsValid(), grid.DirectionValues())
+	jsonBytes, _ := json.Marshal(map[string]grid.Direction{"heading": grid.East})
+	fmt.Println(string(jsonBytes))
+
+	// grid
+	fmt.Println(d.Opposite(), d.TurnLeft(), d.TurnRight(), d.Rotate(1))
+	g, _ := grid.ParseString("S..#\n.#..\n...E\n")
+	start, _ := g.Find('S')
+	end, _ := g.Find('E')
+	path, _ := g.BFS(start, end)
+	fmt.Println(path, start.Manhattan(end), start.Chebyshev(end))
 }
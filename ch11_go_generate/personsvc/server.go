@@ -0,0 +1,121 @@
+// Package personsvc implements the PersonService from person_service.proto
+// on top of a registry.
+package personsvc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"ch11_go_generate/data"
+	"ch11_go_generate/registry"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Store is where the people are kept, registry.Store and registry.Memory
+// both are one.
+type Store interface {
+	Append(people ...*data.Person) error
+	List() ([]*data.Person, error)
+	ByID(id int32) (*data.Person, error)
+	ByEmail(email string) (*data.Person, error)
+	Delete(id int32) error
+}
+
+// Server serves PersonService from a Store.
+type Server struct {
+	data.UnimplementedPersonServiceServer
+
+	store Store
+	// mu makes picking the next free id and appending one step
+	mu sync.Mutex
+}
+
+// NewServer returns a server that keeps the people in store.
+func NewServer(store Store) *Server {
+	return &Server{store: store}
+}
+
+func (s *Server) Create(ctx context.Context, req *data.CreatePersonRequest) (*data.Person, error) {
+	p := req.GetPerson()
+	if p.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "person needs a name")
+	}
+	if p.GetId() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "id can't be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.GetId() == 0 {
+		people, err := s.store.List()
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		var last int32
+		for _, other := range people {
+			last = max(last, other.GetId())
+		}
+		p.Id = last + 1
+	}
+	if err := s.store.Append(p); err != nil {
+		return nil, toStatus(err)
+	}
+	return p, nil
+}
+
+func (s *Server) Get(ctx context.Context, req *data.GetPersonRequest) (*data.Person, error) {
+	var p *data.Person
+	var err error
+	switch key := req.GetKey().(type) {
+	case *data.GetPersonRequest_Id:
+		p, err = s.store.ByID(key.Id)
+	case *data.GetPersonRequest_Email:
+		p, err = s.store.ByEmail(key.Email)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "get needs an id or an email")
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return p, nil
+}
+
+func (s *Server) List(req *data.ListPeopleRequest, stream data.PersonService_ListServer) error {
+	people, err := s.store.List()
+	if err != nil {
+		return toStatus(err)
+	}
+	for _, p := range people {
+		if req.GetTag() != "" && !slices.Contains(p.GetTags(), req.GetTag()) {
+			continue
+		}
+		if err := stream.Send(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *data.DeletePersonRequest) (*emptypb.Empty, error) {
+	if err := s.store.Delete(req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toStatus maps the registry errors to gRPC codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, registry.ErrDuplicate):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,156 @@
+package personsvc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"ch11_go_generate/data"
+	"ch11_go_generate/registry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves store over an in-memory connection and returns a client
+// for it.
+func newClient(t *testing.T, store Store) data.PersonServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	data.RegisterPersonServiceServer(srv, NewServer(store))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return data.NewPersonServiceClient(conn)
+}
+
+func listAll(t *testing.T, client data.PersonServiceClient, tag string) []*data.Person {
+	t.Helper()
+	stream, err := client.List(context.Background(), &data.ListPeopleRequest{Tag: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var people []*data.Person
+	for {
+		p, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return people
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		people = append(people, p)
+	}
+}
+
+func TestPersonService(t *testing.T) {
+	stores := map[string]Store{
+		"memory": registry.NewMemory(),
+		"file":   registry.Open(filepath.Join(t.TempDir(), "people.pb")),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t, store)
+			ctx := context.Background()
+
+			bob, err := client.Create(ctx, &data.CreatePersonRequest{Person: &data.Person{
+				Name: "Bob", Id: 20, Email: "bob@bobson.com", Tags: []string{"admin"},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bob.GetCreatedAt() == nil {
+				t.Error("CreatedAt wasn't set")
+			}
+			alice, err := client.Create(ctx, &data.CreatePersonRequest{Person: &data.Person{Name: "Alice"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if alice.GetId() != 21 {
+				t.Errorf("assigned id %d, want 21", alice.GetId())
+			}
+
+			got, err := client.Get(ctx, &data.GetPersonRequest{Key: &data.GetPersonRequest_Email{Email: "BOB@bobson.com"}})
+			if err != nil || got.GetId() != 20 {
+				t.Errorf("Get by email = %v, %v", got, err)
+			}
+			got, err = client.Get(ctx, &data.GetPersonRequest{Key: &data.GetPersonRequest_Id{Id: 21}})
+			if err != nil || got.GetName() != "Alice" {
+				t.Errorf("Get by id = %v, %v", got, err)
+			}
+
+			if people := listAll(t, client, ""); len(people) != 2 {
+				t.Errorf("List got %d people, want 2", len(people))
+			}
+			if people := listAll(t, client, "admin"); len(people) != 1 || people[0].GetName() != "Bob" {
+				t.Errorf("List with tag = %v", people)
+			}
+
+			if _, err := client.Delete(ctx, &data.DeletePersonRequest{Id: 20}); err != nil {
+				t.Fatal(err)
+			}
+			if people := listAll(t, client, ""); len(people) != 1 || people[0].GetName() != "Alice" {
+				t.Errorf("List after Delete = %v", people)
+			}
+		})
+	}
+}
+
+func TestPersonServiceErrors(t *testing.T) {
+	client := newClient(t, registry.NewMemory())
+	ctx := context.Background()
+	if _, err := client.Create(ctx, &data.CreatePersonRequest{Person: &data.Person{Name: "Bob", Id: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"create without name", func() error {
+			_, err := client.Create(ctx, &data.CreatePersonRequest{Person: &data.Person{Id: 2}})
+			return err
+		}, codes.InvalidArgument},
+		{"create duplicate", func() error {
+			_, err := client.Create(ctx, &data.CreatePersonRequest{Person: &data.Person{Name: "Bob", Id: 1}})
+			return err
+		}, codes.AlreadyExists},
+		{"get without key", func() error {
+			_, err := client.Get(ctx, &data.GetPersonRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"get missing", func() error {
+			_, err := client.Get(ctx, &data.GetPersonRequest{Key: &data.GetPersonRequest_Id{Id: 99}})
+			return err
+		}, codes.NotFound},
+		{"delete missing", func() error {
+			_, err := client.Delete(ctx, &data.DeletePersonRequest{Id: 99})
+			return err
+		}, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
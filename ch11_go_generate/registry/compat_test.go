@@ -0,0 +1,47 @@
+package registry
+
+import (
+	"testing"
+
+	"ch11_go_generate/data"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// testdata/people_v1.pb was written with the original Person, which only
+// had name, id and email.
+func TestOldFilesDecode(t *testing.T) {
+	people, err := Open("testdata/people_v1.pb").List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*data.Person{
+		{Name: "Bob Bobson", Id: 20, Email: "bob@bobson.com"},
+		{Name: "Alice Example", Id: 21, Email: "alice@example.com"},
+	}
+	if len(people) != len(want) {
+		t.Fatalf("got %d people, want %d", len(people), len(want))
+	}
+	for i := range want {
+		if !proto.Equal(people[i], want[i]) {
+			t.Errorf("person %d = %v, want %v", i, people[i], want[i])
+		}
+		if len(people[i].ProtoReflect().GetUnknown()) != 0 {
+			t.Errorf("person %d has unknown fields", i)
+		}
+	}
+}
+
+func TestAppendToOldFile(t *testing.T) {
+	s := copyOf(t, "testdata/people_v1.pb")
+	if err := s.Append(&data.Person{Name: "Carol", Id: 22, Tags: []string{"new"}}); err != nil {
+		t.Fatal(err)
+	}
+	people, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 3 || people[0].GetName() != "Bob Bobson" || people[2].GetTags()[0] != "new" {
+		t.Errorf("got %v", people)
+	}
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"slices"
+	"sync"
+
+	"ch11_go_generate/data"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Memory is a registry that isn't persisted, with the same rules as Store.
+// It's safe for concurrent use.
+type Memory struct {
+	mu     sync.Mutex
+	people []*data.Person
+	now    func() *timestamppb.Timestamp
+}
+
+// NewMemory returns an empty in-memory registry.
+func NewMemory() *Memory {
+	return &Memory{now: timestamppb.Now}
+}
+
+// Append adds people, see Store.Append.
+func (m *Memory) Append(people ...*data.Person) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := checkUnique(m.people, people); err != nil {
+		return err
+	}
+	for _, p := range people {
+		if p.CreatedAt == nil {
+			p.CreatedAt = m.now()
+		}
+		// callers may keep modifying p, like they can with a Store
+		m.people = append(m.people, proto.Clone(p).(*data.Person))
+	}
+	return nil
+}
+
+// List returns copies of everyone in the order they were appended.
+func (m *Memory) List() ([]*data.Person, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]*data.Person, len(m.people))
+	for i, p := range m.people {
+		out[i] = proto.Clone(p).(*data.Person)
+	}
+	return out, nil
+}
+
+// ByID returns a copy of the person with the given id.
+func (m *Memory) ByID(id int32) (*data.Person, error) {
+	people, _ := m.List()
+	return find(people, byID(id))
+}
+
+// ByEmail returns a copy of the person with the given email, ignoring case.
+func (m *Memory) ByEmail(email string) (*data.Person, error) {
+	people, _ := m.List()
+	return find(people, byEmail(email))
+}
+
+// Delete removes the person with the given id.
+func (m *Memory) Delete(id int32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := slices.IndexFunc(m.people, byID(id))
+	if i < 0 {
+		return ErrNotFound
+	}
+	m.people = slices.Delete(m.people, i, i+1)
+	return nil
+}
@@ -0,0 +1,258 @@
+// Package registry keeps people in a file of length-delimited protobuf
+// records: each data.Person is written as its size as a uvarint followed by
+// its wire encoding, the same framing protodelim and the Java and C++
+// writeDelimitedTo use. Appending never rewrites what's already there.
+package registry
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"ch11_go_generate/data"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	// ErrNotFound is returned when no person matches a lookup.
+	ErrNotFound = errors.New("person not found")
+	// ErrDuplicate is returned when appending a person whose id or email is
+	// already taken.
+	ErrDuplicate = errors.New("duplicate person")
+)
+
+// maxRecordSize guards against reading garbage as a huge length.
+const maxRecordSize = 4 << 20
+
+// Store is a registry file. A missing file is an empty registry. It's safe
+// for concurrent use within one process.
+type Store struct {
+	path string
+	now  func() *timestamppb.Timestamp
+
+	mu sync.Mutex
+}
+
+// Open returns the store kept in the file at path, the file is created on
+// the first Append.
+func Open(path string) *Store {
+	return &Store{path: path, now: timestamppb.Now}
+}
+
+// Append adds people to the end of the file. Ids and emails must be unique,
+// if any of them isn't nothing is written. People without CreatedAt get the
+// current time.
+func (s *Store) Append(people ...*data.Person) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.list()
+	if err != nil {
+		return err
+	}
+	if err := checkUnique(existing, people); err != nil {
+		return err
+	}
+
+	var buf []byte
+	for _, p := range people {
+		if p.CreatedAt == nil {
+			p.CreatedAt = s.now()
+		}
+		b, err := proto.Marshal(p)
+		if err != nil {
+			return err
+		}
+		buf = protowire.AppendBytes(buf, b)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// List returns everyone in the order they were appended. If the file is
+// damaged, the people before the damage are returned with the error.
+func (s *Store) List() ([]*data.Person, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+func (s *Store) list() ([]*data.Person, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	people, err := ReadAll(f)
+	if err != nil {
+		return people, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return people, nil
+}
+
+// ByID returns the person with the given id.
+func (s *Store) ByID(id int32) (*data.Person, error) {
+	people, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return find(people, byID(id))
+}
+
+// ByEmail returns the person with the given email, ignoring case.
+func (s *Store) ByEmail(email string) (*data.Person, error) {
+	people, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return find(people, byEmail(email))
+}
+
+// Delete removes the person with the given id. The file is rewritten and
+// replaces the old one atomically.
+func (s *Store) Delete(id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	people, err := s.list()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(people, byID(id))
+	if i < 0 {
+		return ErrNotFound
+	}
+	people = slices.Delete(people, i, i+1)
+
+	var buf []byte
+	for _, p := range people {
+		b, err := proto.Marshal(p)
+		if err != nil {
+			return err
+		}
+		buf = protowire.AppendBytes(buf, b)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func byID(id int32) func(*data.Person) bool {
+	return func(p *data.Person) bool { return p.GetId() == id }
+}
+
+func byEmail(email string) func(*data.Person) bool {
+	return func(p *data.Person) bool { return strings.EqualFold(p.GetEmail(), email) }
+}
+
+func find(people []*data.Person, match func(*data.Person) bool) (*data.Person, error) {
+	i := slices.IndexFunc(people, match)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	return people[i], nil
+}
+
+// checkUnique returns ErrDuplicate if any of people has the id or email of
+// someone in existing or earlier in people.
+func checkUnique(existing, people []*data.Person) error {
+	ids := map[int32]bool{}
+	emails := map[string]bool{}
+	for _, p := range existing {
+		ids[p.GetId()] = true
+		emails[strings.ToLower(p.GetEmail())] = true
+	}
+	for _, p := range people {
+		email := strings.ToLower(p.GetEmail())
+		if ids[p.GetId()] {
+			return fmt.Errorf("%w: id %d", ErrDuplicate, p.GetId())
+		}
+		if email != "" && emails[email] {
+			return fmt.Errorf("%w: email %s", ErrDuplicate, p.GetEmail())
+		}
+		ids[p.GetId()] = true
+		emails[email] = true
+	}
+	return nil
+}
+
+// ReadAll decodes length-delimited records until r is exhausted. On error
+// the records decoded so far are returned too.
+func ReadAll(r io.Reader) ([]*data.Person, error) {
+	br := bufio.NewReader(r)
+	var people []*data.Person
+	for {
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return people, nil
+		}
+		if err != nil {
+			return people, fmt.Errorf("record %d: %w", len(people)+1, err)
+		}
+		if size > maxRecordSize {
+			return people, fmt.Errorf("record %d: size %d exceeds %d bytes", len(people)+1, size, maxRecordSize)
+		}
+		b := make([]byte, size)
+		if _, err := io.ReadFull(br, b); err != nil {
+			return people, fmt.Errorf("record %d: %w", len(people)+1, io.ErrUnexpectedEOF)
+		}
+		p := &data.Person{}
+		if err := proto.Unmarshal(b, p); err != nil {
+			return people, fmt.Errorf("record %d: %w", len(people)+1, err)
+		}
+		people = append(people, p)
+	}
+}
+
+// MarshalJSON encodes people as a JSON array of their protojson form.
+func MarshalJSON(people []*data.Person) ([]byte, error) {
+	raw := make([]json.RawMessage, 0, len(people))
+	for _, p := range people {
+		b, err := protojson.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		raw = append(raw, b)
+	}
+	return json.MarshalIndent(raw, "", "  ")
+}
+
+// UnmarshalJSON decodes what MarshalJSON encodes. Unknown fields are an
+// error, so typos don't get lost silently.
+func UnmarshalJSON(b []byte) ([]*data.Person, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	people := make([]*data.Person, 0, len(raw))
+	for i, r := range raw {
+		p := &data.Person{}
+		if err := protojson.Unmarshal(r, p); err != nil {
+			return nil, fmt.Errorf("person %d: %w", i+1, err)
+		}
+		people = append(people, p)
+	}
+	return people, nil
+}
@@ -0,0 +1,206 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ch11_go_generate/data"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// copyOf returns a store on a copy of the file at path.
+func copyOf(t *testing.T, path string) *Store {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), filepath.Base(path))
+	if err := os.WriteFile(dst, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return Open(dst)
+}
+
+var created = timestamppb.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+func newStore(t *testing.T) *Store {
+	s := Open(filepath.Join(t.TempDir(), "people.pb"))
+	s.now = func() *timestamppb.Timestamp { return created }
+	return s
+}
+
+func TestStore(t *testing.T) {
+	s := newStore(t)
+
+	people, err := s.List()
+	if err != nil || len(people) != 0 {
+		t.Fatalf("empty store: %v, %v", people, err)
+	}
+
+	bob := &data.Person{
+		Name:  "Bob Bobson",
+		Id:    20,
+		Email: "bob@bobson.com",
+		Phones: []*data.Person_PhoneNumber{
+			{Number: "+43 1 234", Type: data.Person_WORK},
+		},
+		Tags: []string{"admin", "ops"},
+	}
+	if err := s.Append(bob); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append(&data.Person{Name: "Alice", Id: 21, Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.ByID(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, bob) || !proto.Equal(got.GetCreatedAt(), created) {
+		t.Errorf("ByID(20) = %v, want %v", got, bob)
+	}
+
+	got, err = s.ByEmail("ALICE@example.com")
+	if err != nil || got.GetId() != 21 {
+		t.Errorf("ByEmail = %v, %v", got, err)
+	}
+
+	if _, err := s.ByID(99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ByID(99) err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.ByEmail("nobody@example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ByEmail err = %v, want ErrNotFound", err)
+	}
+
+	people, err = s.List()
+	if err != nil || len(people) != 2 || people[0].GetId() != 20 || people[1].GetId() != 21 {
+		t.Errorf("List() = %v, %v", people, err)
+	}
+}
+
+func TestAppendDuplicates(t *testing.T) {
+	s := newStore(t)
+	if err := s.Append(&data.Person{Id: 1, Email: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		people []*data.Person
+	}{
+		{"same id", []*data.Person{{Id: 1, Email: "b@example.com"}}},
+		{"same email", []*data.Person{{Id: 2, Email: "A@example.com"}}},
+		{"within the batch", []*data.Person{{Id: 3}, {Id: 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Append(tt.people...); !errors.Is(err, ErrDuplicate) {
+				t.Errorf("err = %v, want ErrDuplicate", err)
+			}
+		})
+	}
+
+	people, err := s.List()
+	if err != nil || len(people) != 1 {
+		t.Errorf("failed appends wrote something: %v, %v", people, err)
+	}
+}
+
+func TestTruncatedFile(t *testing.T) {
+	s := copyOf(t, "testdata/people_v1.pb")
+	info, err := os.Stat(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(s.path, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+	people, err := s.List()
+	if err == nil {
+		t.Fatal("truncated file didn't fail")
+	}
+	if len(people) != 1 {
+		t.Errorf("got %d people before the damaged record, want 1", len(people))
+	}
+}
+
+func TestJSON(t *testing.T) {
+	people := []*data.Person{
+		{Name: "Bob", Id: 20, CreatedAt: created, Tags: []string{"x"},
+			Phones: []*data.Person_PhoneNumber{{Number: "123", Type: data.Person_MOBILE}}},
+		{Name: "Alice", Id: 21},
+	}
+	b, err := MarshalJSON(people)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := UnmarshalJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != len(people) {
+		t.Fatalf("got %d people back, want %d", len(back), len(people))
+	}
+	for i := range people {
+		if !proto.Equal(back[i], people[i]) {
+			t.Errorf("person %d = %v, want %v", i, back[i], people[i])
+		}
+	}
+
+	in := `[{"name": "Eve", "id": 7, "createdAt": "2024-05-01T12:00:00Z", "phones": [{"number": "1", "type": "HOME"}]}]`
+	back, err = UnmarshalJSON([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back[0].GetPhones()[0].GetType() != data.Person_HOME || !proto.Equal(back[0].GetCreatedAt(), created) {
+		t.Errorf("got %v", back[0])
+	}
+
+	if _, err := UnmarshalJSON([]byte(`[{"nmae": "typo"}]`)); err == nil {
+		t.Error("unknown field didn't fail")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	stores := map[string]interface {
+		Append(...*data.Person) error
+		List() ([]*data.Person, error)
+		ByID(int32) (*data.Person, error)
+		Delete(int32) error
+	}{
+		"file":   newStore(t),
+		"memory": NewMemory(),
+	}
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			err := s.Append(&data.Person{Id: 1, Name: "a"}, &data.Person{Id: 2, Name: "b"}, &data.Person{Id: 3, Name: "c"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Delete(2); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Delete(2); !errors.Is(err, ErrNotFound) {
+				t.Errorf("second Delete err = %v, want ErrNotFound", err)
+			}
+			if _, err := s.ByID(2); !errors.Is(err, ErrNotFound) {
+				t.Errorf("ByID after Delete err = %v, want ErrNotFound", err)
+			}
+			people, err := s.List()
+			if err != nil || len(people) != 2 || people[0].GetId() != 1 || people[1].GetId() != 3 {
+				t.Errorf("List() = %v, %v", people, err)
+			}
+			// the id is free again
+			if err := s.Append(&data.Person{Id: 2, Name: "b again"}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
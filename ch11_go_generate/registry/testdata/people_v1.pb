@@ -0,0 +1,4 @@
+
+
+Bob Bobsonbob@bobson.com$
+Alice Examplealice@example.com
\ No newline at end of file
@@ -0,0 +1,85 @@
+// Package breached looks passwords up in a list of known ones without
+// keeping them in plain text. The list is the SHA-1 hashes of the
+// passwords, sorted, 20 bytes each, the format the Have I Been Pwned range
+// files are converted to, so lookups are a binary search.
+package breached
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"io"
+	"slices"
+	"sort"
+)
+
+// HashSize is the size of one entry of a list.
+const HashSize = sha1.Size
+
+// ErrMalformed is returned by Load for data that isn't a sorted list of
+// hashes.
+var ErrMalformed = errors.New("malformed password list")
+
+// List is a sorted set of password hashes.
+type List struct {
+	hashes []byte
+}
+
+// Load returns the list stored in b, as written by Build.
+func Load(b []byte) (*List, error) {
+	if len(b)%HashSize != 0 {
+		return nil, ErrMalformed
+	}
+	for i := HashSize; i < len(b); i += HashSize {
+		if bytes.Compare(b[i-HashSize:i], b[i:i+HashSize]) >= 0 {
+			return nil, ErrMalformed
+		}
+	}
+	return &List{hashes: b}, nil
+}
+
+// Build reads one password per line from r and returns their hashes as a
+// list Load accepts. Empty lines are skipped and duplicates are removed.
+func Build(r io.Reader) ([]byte, error) {
+	var hashes [][HashSize]byte
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := bytes.TrimSuffix(scanner.Bytes(), []byte("\r"))
+		if len(line) == 0 {
+			continue
+		}
+		hashes = append(hashes, sha1.Sum(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(hashes, func(a, b [HashSize]byte) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	hashes = slices.Compact(hashes)
+	out := make([]byte, 0, len(hashes)*HashSize)
+	for _, h := range hashes {
+		out = append(out, h[:]...)
+	}
+	return out, nil
+}
+
+// Len is the number of passwords in the list.
+func (l *List) Len() int {
+	return len(l.hashes) / HashSize
+}
+
+// Contains reports whether password is in the list.
+func (l *List) Contains(password string) bool {
+	h := sha1.Sum([]byte(password))
+	i := sort.Search(l.Len(), func(i int) bool {
+		return bytes.Compare(l.entry(i), h[:]) >= 0
+	})
+	return i < l.Len() && bytes.Equal(l.entry(i), h[:])
+}
+
+func (l *List) entry(i int) []byte {
+	return l.hashes[i*HashSize : (i+1)*HashSize]
+}
@@ -0,0 +1,61 @@
+package breached
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	hashes, err := Build(strings.NewReader("hunter2\r\npassword\n\n123456\npassword\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := Load(hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", list.Len())
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"hunter2", true},
+		{"password", true},
+		{"123456", true},
+		{"Password", false},
+		{"", false},
+		{"hunter22", false},
+	}
+	for _, tt := range tests {
+		if got := list.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestLoadMalformed(t *testing.T) {
+	hashes, err := Build(strings.NewReader("a\nb\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapped := append(append([]byte{}, hashes[HashSize:]...), hashes[:HashSize]...)
+
+	for name, b := range map[string][]byte{
+		"truncated": hashes[:len(hashes)-1],
+		"unsorted":  swapped,
+		"duplicate": append(hashes[:HashSize:HashSize], hashes[:HashSize]...),
+	} {
+		if _, err := Load(b); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: err = %v, want ErrMalformed", name, err)
+		}
+	}
+
+	empty, err := Load(nil)
+	if err != nil || empty.Contains("a") {
+		t.Errorf("empty list: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Command hashlist turns a file with one password per line into the sorted
+// hash list the breached package reads, it's run by go generate.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"ch11_tooling_embedding/breached"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("hashlist: ")
+	in := flag.String("in", "passwords.txt", "file with one password per line")
+	out := flag.String("out", "passwords.sha1", "file to write the hashes to")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	hashes, err := breached.Build(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, hashes, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -2,23 +2,58 @@ package main
 
 import (
 	_ "embed"
+	"flag"
 	"fmt"
+	"log"
 	"os"
-	"strings"
+
+	"ch11_tooling_embedding/breached"
+	"ch11_tooling_embedding/policy"
 )
 
-//go:embed passwords.txt
-var passwords string
+// passwords.txt isn't embedded, only the hashes of its passwords are.
+//
+//go:generate go run ./cmd/hashlist -in passwords.txt -out passwords.sha1
+
+//go:embed passwords.sha1
+var passwordHashes []byte
 
 func main() {
-	pwds := strings.Split(passwords, "\n")
-	if len(os.Args) > 1 {
-		for _, v := range pwds {
-			if v == os.Args[1] {
-				fmt.Println("true")
-				os.Exit(0)
-			}
+	log.SetFlags(0)
+	minLength := flag.Int("min-length", 12, "characters a password needs")
+	minClasses := flag.Int("min-classes", 3, "character classes a password needs")
+	minEntropy := flag.Float64("min-entropy", 50, "estimated bits of entropy a password needs")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: ch11_tooling_embedding [flags] password...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	list, err := breached.Load(passwordHashes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checker := policy.New(
+		policy.WithMinLength(*minLength),
+		policy.WithMinClasses(*minClasses),
+		policy.WithMinEntropy(*minEntropy),
+		policy.WithBreached(list),
+	)
+
+	weak := false
+	for _, password := range flag.Args() {
+		report := checker.Check(password)
+		if !report.Weak() {
+			fmt.Printf("%q is strong, estimated entropy %.0f bits\n", password, report.Entropy)
+			continue
 		}
-		fmt.Println("false")
+		weak = true
+		fmt.Printf("%q is weak, it\n", password)
+		for _, p := range report.Problems {
+			fmt.Printf("  - %s (%s)\n", p.Message, p.Rule)
+		}
+	}
+	if weak {
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyboardRows are checked forwards and backwards, the digits are left out
+// since they're a plain sequence
+var keyboardRows = []string{
+	"qwertyuiop[]",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"qwertzuiop",
+	"yxcvbnm",
+}
+
+// commonWords are the parts people build passwords from most often
+var commonWords = []string{
+	"password", "passwort", "admin", "letmein", "welcome", "monkey",
+	"dragon", "master", "login", "secret", "iloveyou", "football",
+}
+
+// leet maps the characters leetspeak substitutes back to letters
+var leet = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t",
+	"@", "a", "$", "s", "!", "i", "|", "l",
+)
+
+func unleet(password string) string {
+	return leet.Replace(strings.ToLower(password))
+}
+
+// patterns describes the guessable parts of password.
+func patterns(password string) []string {
+	var found []string
+	lower := []rune(strings.ToLower(password))
+
+	if run := longestRun(lower, func(prev, r rune) bool { return r == prev }); len(run) >= 3 {
+		found = append(found, fmt.Sprintf("repeats %q", string(run)))
+	}
+	if run := longestRun(lower, func(prev, r rune) bool { return r == prev+1 }); len(run) >= 4 {
+		found = append(found, fmt.Sprintf("contains the sequence %q", string(run)))
+	} else if run := longestRun(lower, func(prev, r rune) bool { return r == prev-1 }); len(run) >= 4 {
+		found = append(found, fmt.Sprintf("contains the sequence %q", string(run)))
+	}
+	if s, ok := keyboardWalk(string(lower)); ok {
+		found = append(found, fmt.Sprintf("contains the keyboard sequence %q", s))
+	}
+	if year, ok := findYear(lower); ok {
+		found = append(found, fmt.Sprintf("contains the year %s", year))
+	}
+	unleeted := unleet(password)
+	for _, w := range commonWords {
+		if strings.Contains(unleeted, w) {
+			found = append(found, fmt.Sprintf("contains the common word %q", w))
+			break
+		}
+	}
+	return found
+}
+
+// longestRun returns the longest part of s in which every rune follows
+// from the one before it.
+func longestRun(s []rune, follows func(prev, r rune) bool) []rune {
+	var best []rune
+	start := 0
+	for i := 1; i <= len(s); i++ {
+		if i < len(s) && follows(s[i-1], s[i]) {
+			continue
+		}
+		if i-start > len(best) {
+			best = s[start:i]
+		}
+		start = i
+	}
+	return best
+}
+
+// keyboardWalk finds four or more neighbouring keys of one row.
+func keyboardWalk(lower string) (string, bool) {
+	const minWalk = 4
+	for _, row := range keyboardRows {
+		for _, r := range []string{row, reverse(row)} {
+			for i := 0; i+minWalk <= len(r); i++ {
+				if strings.Contains(lower, r[i:i+minWalk]) {
+					// extend it as far as it goes
+					j := i + minWalk
+					for j < len(r) && strings.Contains(lower, r[i:j+1]) {
+						j++
+					}
+					return r[i:j], true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// findYear finds four digits from 1900 to 2099 that aren't part of a longer
+// number.
+func findYear(s []rune) (string, bool) {
+	isDigit := func(i int) bool { return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9' }
+	for i := 0; i+4 <= len(s); i++ {
+		if !isDigit(i) || !isDigit(i+1) || !isDigit(i+2) || !isDigit(i+3) || isDigit(i-1) || isDigit(i+4) {
+			continue
+		}
+		year := string(s[i : i+4])
+		if year[:2] == "19" || year[:2] == "20" {
+			return year, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,197 @@
+// Package policy checks passwords against a password policy and explains
+// what's wrong with the ones that fail it.
+package policy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Problem is one reason a password is weak.
+type Problem struct {
+	// Rule is the rule that failed: "length", "classes", "entropy",
+	// "pattern" or "breached".
+	Rule    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Rule + ": " + p.Message
+}
+
+// Report is the result of checking a password.
+type Report struct {
+	// Entropy is a rough estimate of the bits an attacker has to guess.
+	Entropy  float64
+	Problems []Problem
+}
+
+// Weak reports whether the password broke any rule.
+func (r Report) Weak() bool {
+	return len(r.Problems) > 0
+}
+
+// Breached is a list of passwords known to be leaked, *breached.List is
+// one.
+type Breached interface {
+	Contains(password string) bool
+}
+
+// Checker checks passwords, the zero value isn't usable, use New.
+type Checker struct {
+	minLength  int
+	minClasses int
+	minEntropy float64
+	breached   Breached
+}
+
+// Option configures a Checker.
+type Option func(*Checker)
+
+// WithMinLength sets how many characters a password needs, 12 by default.
+func WithMinLength(n int) Option {
+	return func(c *Checker) {
+		c.minLength = n
+	}
+}
+
+// WithMinClasses sets how many of lowercase letters, uppercase letters,
+// digits and symbols a password needs, 3 by default.
+func WithMinClasses(n int) Option {
+	return func(c *Checker) {
+		c.minClasses = n
+	}
+}
+
+// WithMinEntropy sets the estimated entropy in bits a password needs, 50 by
+// default.
+func WithMinEntropy(bits float64) Option {
+	return func(c *Checker) {
+		c.minEntropy = bits
+	}
+}
+
+// WithBreached rejects passwords in list, also when they're only disguised
+// with leetspeak like "p4ssw0rd".
+func WithBreached(list Breached) Option {
+	return func(c *Checker) {
+		c.breached = list
+	}
+}
+
+// New returns a Checker with the default policy changed by opts.
+func New(opts ...Option) *Checker {
+	c := &Checker{
+		minLength:  12,
+		minClasses: 3,
+		minEntropy: 50,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Check returns the problems of password, the report of a strong password
+// has none.
+func (c *Checker) Check(password string) Report {
+	var r Report
+	add := func(rule, format string, args ...any) {
+		r.Problems = append(r.Problems, Problem{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n := utf8.RuneCountInString(password); n < c.minLength {
+		add("length", "is %d characters long, at least %d are needed", n, c.minLength)
+	}
+
+	present := classesOf(password)
+	if len(present) < c.minClasses {
+		desc := "no characters"
+		if len(present) > 0 {
+			desc = "only " + join(present)
+		}
+		add("classes", "uses %s, at least %d of lowercase letters, uppercase letters, digits and symbols are needed", desc, c.minClasses)
+	}
+
+	r.Entropy = entropy(password)
+	if r.Entropy < c.minEntropy {
+		add("entropy", "has an estimated entropy of %.0f bits, at least %.0f are needed", r.Entropy, c.minEntropy)
+	}
+
+	for _, p := range patterns(password) {
+		add("pattern", "%s", p)
+	}
+
+	if c.breached != nil {
+		if c.breached.Contains(password) {
+			add("breached", "appears in a list of leaked passwords")
+		} else if unleet := unleet(password); unleet != strings.ToLower(password) && c.breached.Contains(unleet) {
+			add("breached", "is the leaked password %q in leetspeak", unleet)
+		}
+	}
+	return r
+}
+
+type class struct {
+	name string
+	size int
+	is   func(rune) bool
+}
+
+// classes are the kinds of characters and how many there are of each,
+// anything else counts as a symbol
+var classes = []class{
+	{"lowercase letters", 26, unicode.IsLower},
+	{"uppercase letters", 26, unicode.IsUpper},
+	{"digits", 10, unicode.IsDigit},
+	{"symbols", 33, func(r rune) bool { return !unicode.IsLower(r) && !unicode.IsUpper(r) && !unicode.IsDigit(r) }},
+}
+
+func classesOf(password string) []string {
+	var present []string
+	for _, c := range classes {
+		if strings.IndexFunc(password, c.is) >= 0 {
+			present = append(present, c.name)
+		}
+	}
+	return present
+}
+
+func join(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// entropy estimates the bits of password as if every character was picked
+// at random from the classes it uses, except that a character repeating or
+// continuing a sequence with the previous one only counts as one bit.
+func entropy(password string) float64 {
+	pool := 0
+	for _, c := range classes {
+		if strings.IndexFunc(password, c.is) >= 0 {
+			pool += c.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	perRune := math.Log2(float64(pool))
+
+	bits := 0.0
+	prev := rune(-1)
+	for _, r := range password {
+		lr := unicode.ToLower(r)
+		if d := lr - prev; d >= -1 && d <= 1 {
+			bits++
+		} else {
+			bits += perRune
+		}
+		prev = lr
+	}
+	return bits
+}
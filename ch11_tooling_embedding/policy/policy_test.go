@@ -0,0 +1,82 @@
+package policy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type fakeBreached []string
+
+func (f fakeBreached) Contains(password string) bool {
+	return slices.Contains(f, password)
+}
+
+func rules(r Report) []string {
+	var out []string
+	for _, p := range r.Problems {
+		out = append(out, p.Rule)
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	c := New(WithBreached(fakeBreached{"hunter2", "dragonfly"}))
+
+	tests := []struct {
+		password string
+		rules    []string
+		message  string
+	}{
+		{"correct-Horse-battery-7", nil, ""},
+		{"Tr0ub4dor&3xq", nil, ""},
+		{"", []string{"length", "classes", "entropy"}, "uses no characters"},
+		{"hunter2", []string{"length", "classes", "entropy", "breached"}, "leaked"},
+		{"aaaaaaaaaaaaaaaa", []string{"classes", "entropy", "pattern"}, `repeats "aaaaaaaaaaaaaaaa"`},
+		{"xK9#mw2abcdeQ!", []string{"pattern"}, `sequence "abcde"`},
+		{"Zq!7lp54321vW", []string{"pattern"}, `sequence "54321"`},
+		{"Asdfgh!8Kq2#r", []string{"pattern"}, `keyboard sequence "asdfgh"`},
+		{"Mx!k9Wr2008#pz", []string{"pattern"}, "year 2008"},
+		{"Xy#P@ssw0rd!zz", []string{"pattern"}, `common word "password"`},
+		{"Dr4g0nfly", []string{"length", "pattern", "breached"}, `"dragonfly" in leetspeak`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			r := c.Check(tt.password)
+			if got := rules(r); !slices.Equal(got, tt.rules) {
+				t.Errorf("rules = %v, want %v (%v)", got, tt.rules, r.Problems)
+			}
+			if r.Weak() != (len(tt.rules) > 0) {
+				t.Errorf("Weak() = %v", r.Weak())
+			}
+			if tt.message != "" && !slices.ContainsFunc(r.Problems, func(p Problem) bool {
+				return strings.Contains(p.Message, tt.message)
+			}) {
+				t.Errorf("no problem mentions %q: %v", tt.message, r.Problems)
+			}
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	c := New(WithMinLength(4), WithMinClasses(1), WithMinEntropy(10))
+	if r := c.Check("kx9w"); r.Weak() {
+		t.Errorf("relaxed policy: %v", r.Problems)
+	}
+	if r := New().Check("kx9w"); !r.Weak() {
+		t.Error("default policy accepted kx9w")
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	// repeats and sequences add little
+	if a, b := entropy("aaaaaaaa"), entropy("a"); a-b > 8 {
+		t.Errorf("entropy(aaaaaaaa) = %.1f, entropy(a) = %.1f", a, b)
+	}
+	if a, b := entropy("kx9wq2"), entropy("kx9"); a <= b {
+		t.Errorf("longer password has less entropy: %.1f <= %.1f", a, b)
+	}
+	if a, b := entropy("kx9wq2"), entropy("kX9w#2"); a >= b {
+		t.Errorf("more classes gave less entropy: %.1f >= %.1f", b, a)
+	}
+}
@@ -0,0 +1,99 @@
+// Package clock lets code that depends on time be tested without waiting
+// for it. Code takes a Clock instead of calling the time package directly,
+// production passes Real and tests a Fake that only moves when told to.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the part of the time package that depends on the current time.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f once d has passed. The Real clock calls it in its
+	// own goroutine, the Fake one in the goroutine advancing it.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a *time.Timer of a Clock.
+type Timer interface {
+	// C is nil for timers made by AfterFunc.
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a *time.Ticker of a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// Real is the clock of the time package.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// WithTimeout is context.WithTimeout with the deadline measured by c. Once
+// it passes, the context's Err is context.DeadlineExceeded, like for
+// context.WithTimeout.
+func WithTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := c.(realClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	deadline := c.Now().Add(d)
+	if parent, ok := ctx.Deadline(); ok && parent.Before(deadline) {
+		// ctx is done first, its cancellation is passed on below
+		deadline = parent
+	}
+	tc := &timerCtx{
+		Context:  ctx,
+		deadline: deadline,
+		done:     make(chan struct{}),
+	}
+	tc.setStops(
+		c.AfterFunc(d, func() { tc.cancel(context.DeadlineExceeded) }),
+		context.AfterFunc(ctx, func() { tc.cancel(ctx.Err()) }),
+	)
+	return tc, func() { tc.cancel(context.Canceled) }
+}
@@ -0,0 +1,204 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	f.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	f.Advance(time.Millisecond)
+	select {
+	case now := <-timer.C():
+		if want := epoch.Add(time.Second); !now.Equal(want) {
+			t.Error("expected", want, "got", now)
+		}
+	default:
+		t.Fatal("timer didn't fire")
+	}
+	if d := f.Since(epoch); d != time.Second {
+		t.Error("expected 1s to have passed, got", d)
+	}
+}
+
+func TestFakeTimerStopReset(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	if !timer.Stop() {
+		t.Error("expected Stop to stop a waiting timer")
+	}
+	if timer.Stop() {
+		t.Error("expected a second Stop to report false")
+	}
+	f.Advance(time.Second)
+	if timer.Reset(time.Second) {
+		t.Error("expected Reset of a stopped timer to report false")
+	}
+	f.Advance(time.Second)
+	if got := <-timer.C(); !got.Equal(epoch.Add(2 * time.Second)) {
+		t.Error("expected the reset timer to fire at 2s, got", got)
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+	for i := 1; i <= 3; i++ {
+		f.Advance(time.Second)
+		if got, want := <-ticker.C(), epoch.Add(time.Duration(i)*time.Second); !got.Equal(want) {
+			t.Error("expected tick at", want, "got", got)
+		}
+	}
+
+	// ticks nobody reads are dropped rather than blocking Advance
+	f.Advance(5 * time.Second)
+	if got, want := <-ticker.C(), epoch.Add(4*time.Second); !got.Equal(want) {
+		t.Error("expected the first missed tick at", want, "got", got)
+	}
+
+	ticker.Reset(time.Minute)
+	f.Advance(59 * time.Second)
+	ticker.Stop()
+	f.Advance(time.Hour)
+	select {
+	case got := <-ticker.C():
+		t.Error("unexpected tick at", got)
+	default:
+	}
+}
+
+func TestFakeAfterFuncOrder(t *testing.T) {
+	f := NewFake(epoch)
+	var fired []time.Duration
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second} {
+		f.AfterFunc(d, func() {
+			// the clock shows the time the function was due at
+			fired = append(fired, f.Since(epoch))
+		})
+	}
+	f.Advance(time.Minute)
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if len(fired) != len(want) {
+		t.Fatal("expected", want, "got", fired)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Error("expected", want, "got", fired)
+			break
+		}
+	}
+	if d := f.Since(epoch); d != time.Minute {
+		t.Error("expected the clock at 1m, got", d)
+	}
+}
+
+func TestFakeAfterFuncImmediate(t *testing.T) {
+	f := NewFake(epoch)
+	called := false
+	f.AfterFunc(0, func() { called = true })
+	if !called {
+		t.Error("expected AfterFunc(0) to call f right away")
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Second)
+		close(done)
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	<-done
+}
+
+func TestWithTimeoutFake(t *testing.T) {
+	f := NewFake(epoch)
+	ctx, cancel := WithTimeout(context.Background(), f, time.Second)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(epoch.Add(time.Second)) {
+		t.Error("expected deadline at 1s, got", deadline, ok)
+	}
+	child, cancelChild := context.WithCancel(ctx)
+	defer cancelChild()
+
+	f.Advance(999 * time.Millisecond)
+	if err := ctx.Err(); err != nil {
+		t.Fatal("expected no error before the deadline, got", err)
+	}
+	f.Advance(time.Millisecond)
+	<-child.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", ctx.Err())
+	}
+	if !errors.Is(child.Err(), context.DeadlineExceeded) {
+		t.Error("expected the child to report context.DeadlineExceeded, got", child.Err())
+	}
+}
+
+func TestWithTimeoutFakeParentDeadline(t *testing.T) {
+	f := NewFake(epoch)
+	parent, cancelParent := WithTimeout(context.Background(), f, time.Second)
+	defer cancelParent()
+	ctx, cancel := WithTimeout(parent, f, time.Minute)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(epoch.Add(time.Second)) {
+		t.Error("expected the earlier deadline of the parent, got", deadline)
+	}
+	f.Advance(time.Second)
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", ctx.Err())
+	}
+}
+
+func TestWithTimeoutFakeCancel(t *testing.T) {
+	f := NewFake(epoch)
+	ctx, cancel := WithTimeout(context.Background(), f, time.Second)
+	cancel()
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Error("expected context.Canceled, got", ctx.Err())
+	}
+	f.Advance(time.Second)
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Error("expected the deadline not to override the cancel, got", ctx.Err())
+	}
+}
+
+func TestWithTimeoutFakeParent(t *testing.T) {
+	type key struct{}
+	f := NewFake(epoch)
+	parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	ctx, cancel := WithTimeout(parent, f, time.Second)
+	defer cancel()
+	if ctx.Value(key{}) != "v" {
+		t.Error("expected the values of the parent")
+	}
+	cancelParent()
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Error("expected context.Canceled, got", ctx.Err())
+	}
+}
+
+func TestWithTimeoutReal(t *testing.T) {
+	ctx, cancel := WithTimeout(context.Background(), Real, time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", ctx.Err())
+	}
+}
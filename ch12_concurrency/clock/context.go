@@ -0,0 +1,69 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// timerCtx is a context cancelled by a Timer of any Clock. It doesn't use a
+// context.WithCancel underneath, contexts derived from it would report
+// context.Canceled instead of its own error then.
+type timerCtx struct {
+	context.Context // the parent, for Value
+	deadline        time.Time
+	done            chan struct{}
+
+	mu         sync.Mutex
+	err        error
+	timer      Timer
+	stopParent func() bool
+}
+
+func (c *timerCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timerCtx) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timerCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// setStops hands over what cancel has to stop. Either may already have
+// called cancel, in that case they're stopped right away.
+func (c *timerCtx) setStops(timer Timer, stopParent func() bool) {
+	c.mu.Lock()
+	cancelled := c.err != nil
+	if !cancelled {
+		c.timer, c.stopParent = timer, stopParent
+	}
+	c.mu.Unlock()
+	if cancelled {
+		timer.Stop()
+		stopParent()
+	}
+}
+
+func (c *timerCtx) cancel(err error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.err = err
+	close(c.done)
+	timer, stopParent := c.timer, c.stopParent
+	c.mu.Unlock()
+
+	if timer != nil {
+		timer.Stop()
+	}
+	if stopParent != nil {
+		stopParent()
+	}
+}
@@ -0,0 +1,205 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that stands still until Advance moves it. Timers and
+// tickers fire while it's advanced, in the order of their times, and
+// AfterFunc functions are called in the goroutine calling Advance, so once
+// Advance returns everything that was due has happened.
+type Fake struct {
+	mu sync.Mutex
+	// changed is signalled whenever a timer is added, for BlockUntil
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// NewFake returns a fake clock showing start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock was advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.NewTimer(d).C()
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{f: f, ch: make(chan time.Time, 1)}
+	f.schedule(t, d)
+	return t
+}
+
+// NewTicker panics if d isn't positive, like time.NewTicker.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{f: f, ch: make(chan time.Time, 1), period: d}
+	f.schedule(t, d)
+	return fakeTicker{t}
+}
+
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	t := &fakeTimer{f: f, fn: fn}
+	f.schedule(t, d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing what becomes due on the way.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.fireUntil(f.now.Add(d))
+	f.mu.Unlock()
+}
+
+// BlockUntil waits until at least n timers, tickers or sleeping goroutines
+// are waiting for the clock. Tests call it before Advance, to be sure the
+// code under test got as far as starting its timers.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.changed.Wait()
+	}
+}
+
+// schedule (re)starts t to fire after d, right away if d isn't positive.
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.when = f.now.Add(d)
+	f.timers = append(f.timers, t)
+	f.changed.Broadcast()
+	f.fireUntil(f.now)
+}
+
+// fireUntil fires the timers due until end in order and moves the clock to
+// end. f.mu is held when it's called and returned, but released while
+// firing, so AfterFunc functions can use the clock.
+func (f *Fake) fireUntil(end time.Time) {
+	for {
+		i := f.next()
+		if i < 0 || f.timers[i].when.After(end) {
+			break
+		}
+		t := f.timers[i]
+		if t.when.After(f.now) {
+			f.now = t.when
+		}
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			f.timers = slices.Delete(f.timers, i, i+1)
+		}
+		now := f.now
+
+		f.mu.Unlock()
+		t.fire(now)
+		f.mu.Lock()
+	}
+	if end.After(f.now) {
+		f.now = end
+	}
+}
+
+// next returns the index of the timer due first, -1 if there's none.
+func (f *Fake) next() int {
+	next := -1
+	for i, t := range f.timers {
+		if next < 0 || t.when.Before(f.timers[next].when) {
+			next = i
+		}
+	}
+	return next
+}
+
+// remove stops t, it reports whether t was still waiting.
+func (f *Fake) remove(t *fakeTimer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := slices.Index(f.timers, t)
+	if i < 0 {
+		return false
+	}
+	f.timers = slices.Delete(f.timers, i, i+1)
+	return true
+}
+
+// fakeTimer is a timer, ticker or AfterFunc of a Fake.
+type fakeTimer struct {
+	f  *Fake
+	ch chan time.Time
+	fn func()
+	// period and when are guarded by f.mu, period is 0 for timers
+	period time.Duration
+	when   time.Time
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	if t.fn != nil {
+		t.fn()
+		return
+	}
+	// like the channels of the time package, a reader that's too slow
+	// misses ticks instead of blocking the clock
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.f.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	active := t.f.remove(t)
+	t.f.schedule(t, d)
+	return active
+}
+
+// fakeTicker is a fakeTimer that's rescheduled every period.
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t fakeTicker) Stop() {
+	t.f.remove(t.fakeTimer)
+}
+
+// Reset panics if d isn't positive, like (*time.Ticker).Reset.
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.f.remove(t.fakeTimer)
+	t.f.mu.Lock()
+	t.period = d
+	t.f.mu.Unlock()
+	t.f.schedule(t.fakeTimer, d)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"ch12_concurrency/leakcheck"
+)
+
+func TestCountToStopsOnCancel(t *testing.T) {
+	leakcheck.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var last int
+	for i := range countTo(ctx, 100) {
+		last = i
+		if i == 5 {
+			break
+		}
+	}
+	if last != 5 {
+		t.Error("expected 5, got", last)
+	}
+}
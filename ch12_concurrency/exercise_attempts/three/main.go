@@ -3,25 +3,25 @@ package main
 import (
 	"fmt"
 	"math"
-)
-import "sync"
 
-var cache = map[int]float64{}
-var initCacheOnce = sync.OnceFunc(initCache)
+	"ch12_concurrency/memo"
+)
 
-func initCache() {
-	fmt.Println("Init cache")
-	for i := 0; i < 100_000; i++ {
-		cache[i] = math.Sqrt(float64(i))
-	}
-}
+// instead of eagerly computing 100,000 square roots up front, each root is
+// computed the first time it's looked up and kept in a bounded cache
+var cache = memo.NewWithHash(func(key int) (float64, error) {
+	return math.Sqrt(float64(key)), nil
+}, func(key int) uint64 {
+	return uint64(key)
+}, memo.WithCapacity(100_000))
 
 func lookup(key int) float64 {
-	initCacheOnce()
-	return cache[key]
+	v, _ := cache.Get(key)
+	return v
 }
 
 func main() {
 	fmt.Println(lookup(1))
 	fmt.Println(lookup(2000))
+	fmt.Printf("%+v\n", cache.Metrics())
 }
@@ -25,8 +25,10 @@ func processConcurrently(inVals []int) []int {
 			}
 		}()
 	}
-	// load the data into the channel in another goroutine
+	// load the data into the channel in another goroutine. closing `in`
+	// afterward ends the workers' for-range loops, otherwise they leak
 	go func() {
+		defer close(in)
 		for _, v := range inVals {
 			in <- v
 		}
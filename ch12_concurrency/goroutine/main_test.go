@@ -0,0 +1,18 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"ch12_concurrency/leakcheck"
+)
+
+func TestProcessConcurrently(t *testing.T) {
+	leakcheck.Check(t)
+	result := processConcurrently([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	slices.Sort(result)
+	expected := []int{2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24}
+	if !slices.Equal(expected, result) {
+		t.Error("expected", expected, "got", result)
+	}
+}
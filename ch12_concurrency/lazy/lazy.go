@@ -0,0 +1,111 @@
+// Package lazy provides a concurrency-safe, lazily initialized value. Unlike
+// sync.Once it copes with initializers that can fail, can be reset and can
+// refresh its value after a TTL.
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ch12_concurrency/clock"
+)
+
+type entry[T any] struct {
+	val     T
+	expires time.Time
+}
+
+// Lazy holds a value that is computed by init on first use. A failed init
+// isn't cached, the next call to Get simply tries again. Once a value is
+// ready, Get never blocks: it's served through an atomic load, and when the
+// value has outlived its TTL it's refreshed in the background while callers
+// keep getting the old one.
+type Lazy[T any] struct {
+	init  func() (T, error)
+	ttl   time.Duration
+	clock clock.Clock
+
+	current    atomic.Pointer[entry[T]]
+	refreshing atomic.Bool
+
+	// mu serializes init calls, including the ones of background refreshes,
+	// and Reset
+	mu sync.Mutex
+}
+
+// New returns a Lazy that calls init on first use and keeps its result
+// until Reset is called.
+func New[T any](init func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{init: init, clock: clock.Real}
+}
+
+// NewWithTTL returns a Lazy whose value is refreshed once it's older than
+// ttl as measured by c. If the refresh fails, the old value is kept and the
+// refresh is tried again on the next Get.
+func NewWithTTL[T any](init func() (T, error), ttl time.Duration, c clock.Clock) *Lazy[T] {
+	return &Lazy[T]{init: init, ttl: ttl, clock: c}
+}
+
+// Get returns the value, calling init if there is none yet. Concurrent
+// callers that arrive while init runs wait for it and share its result.
+func (l *Lazy[T]) Get() (T, error) {
+	if e := l.current.Load(); e != nil {
+		if l.expired(e) && l.refreshing.CompareAndSwap(false, true) {
+			go l.refresh()
+		}
+		return e.val, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// somebody else might have finished init while we waited for the lock
+	if e := l.current.Load(); e != nil {
+		return e.val, nil
+	}
+	val, err := l.init()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	l.current.Store(l.newEntry(val))
+	return val, nil
+}
+
+// Reset drops the current value, the next Get calls init again.
+func (l *Lazy[T]) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.current.Store(nil)
+}
+
+func (l *Lazy[T]) refresh() {
+	defer l.refreshing.Store(false)
+
+	// Get doesn't take mu once there's a value, so holding it while init
+	// runs only holds up Reset and a first load
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// a Reset while we waited for the lock dropped the value, and a Get
+	// after it may already have loaded a fresh one
+	if e := l.current.Load(); e == nil || !l.expired(e) {
+		return
+	}
+	val, err := l.init()
+	if err != nil {
+		return
+	}
+	l.current.Store(l.newEntry(val))
+}
+
+func (l *Lazy[T]) newEntry(val T) *entry[T] {
+	e := &entry[T]{val: val}
+	if l.ttl > 0 {
+		e.expires = l.clock.Now().Add(l.ttl)
+	}
+	return e
+}
+
+func (l *Lazy[T]) expired(e *entry[T]) bool {
+	return l.ttl > 0 && l.clock.Now().After(e.expires)
+}
@@ -0,0 +1,192 @@
+package lazy
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ch12_concurrency/clock"
+	"ch12_concurrency/leakcheck"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestGetCallsInitOnce(t *testing.T) {
+	leakcheck.Check(t)
+	var calls atomic.Int32
+	l := New(func() (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer wg.Done()
+			if v, err := l.Get(); v != 42 || err != nil {
+				t.Error("expected 42 <nil>, got", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Error("expected init to be called once, got", calls.Load())
+	}
+}
+
+func TestGetRetriesFailedInit(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	l := New(func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "", boom
+		}
+		return "ok", nil
+	})
+
+	if _, err := l.Get(); !errors.Is(err, boom) {
+		t.Error("expected boom, got", err)
+	}
+	if v, err := l.Get(); v != "ok" || err != nil {
+		t.Error("expected ok <nil>, got", v, err)
+	}
+	if calls != 2 {
+		t.Error("expected 2 calls, got", calls)
+	}
+}
+
+func TestReset(t *testing.T) {
+	calls := 0
+	l := New(func() (int, error) {
+		calls++
+		return calls, nil
+	})
+
+	if v, _ := l.Get(); v != 1 {
+		t.Error("expected 1, got", v)
+	}
+	l.Reset()
+	if v, _ := l.Get(); v != 2 {
+		t.Error("expected 2, got", v)
+	}
+}
+
+// waitForRefresh returns once the background refresh that called init has
+// finished. refresh holds mu from before init is called until the new value
+// is stored.
+func waitForRefresh[T any](l *Lazy[T]) {
+	l.mu.Lock()
+	l.mu.Unlock()
+}
+
+func TestTTLRefresh(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(epoch)
+	var calls atomic.Int32
+	refreshing := make(chan struct{})
+	l := NewWithTTL(func() (int32, error) {
+		n := calls.Add(1)
+		if n == 2 {
+			close(refreshing)
+		}
+		return n, nil
+	}, 10*time.Millisecond, fake)
+
+	if v, _ := l.Get(); v != 1 {
+		t.Error("expected 1, got", v)
+	}
+	fake.Advance(10 * time.Millisecond)
+	if v, _ := l.Get(); v != 1 || calls.Load() != 1 {
+		t.Error("expected 1 without a refresh right at the TTL, got", v, "after", calls.Load(), "calls")
+	}
+	fake.Advance(time.Millisecond)
+	// the expired value is still served while the refresh runs
+	if v, _ := l.Get(); v != 1 {
+		t.Error("expected stale 1, got", v)
+	}
+	<-refreshing
+	waitForRefresh(l)
+	if v, _ := l.Get(); v != 2 {
+		t.Error("expected refreshed 2, got", v)
+	}
+}
+
+func TestTTLRefreshFailureKeepsValue(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(epoch)
+	var calls atomic.Int32
+	failed := make(chan struct{}, 1)
+	l := NewWithTTL(func() (int, error) {
+		if calls.Add(1) > 1 {
+			select {
+			case failed <- struct{}{}:
+			default:
+			}
+			return 0, errors.New("boom")
+		}
+		return 1, nil
+	}, time.Millisecond, fake)
+
+	l.Get()
+	fake.Advance(5 * time.Millisecond)
+	if v, err := l.Get(); v != 1 || err != nil {
+		t.Error("expected stale 1 <nil>, got", v, err)
+	}
+	<-failed
+	waitForRefresh(l)
+	if v, err := l.Get(); v != 1 || err != nil {
+		t.Error("expected 1 <nil> after the failed refresh, got", v, err)
+	}
+}
+
+func TestRefreshHoldsOffFirstLoad(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(epoch)
+	var calls, running, overlaps atomic.Int32
+	entered, release := make(chan struct{}), make(chan struct{})
+	l := NewWithTTL(func() (int32, error) {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer running.Add(-1)
+		n := calls.Add(1)
+		if n == 2 {
+			// the refresh
+			close(entered)
+			<-release
+		}
+		return n, nil
+	}, time.Millisecond, fake)
+
+	l.Get()
+	fake.Advance(2 * time.Millisecond)
+	if v, _ := l.Get(); v != 1 {
+		t.Error("expected stale 1, got", v)
+	}
+	<-entered
+
+	got := make(chan int32)
+	go func() {
+		l.Reset()
+		v, _ := l.Get()
+		got <- v
+	}()
+	select {
+	case v := <-got:
+		t.Error("Reset and Get didn't wait for the refresh, got", v)
+		close(release)
+	case <-time.After(20 * time.Millisecond):
+		close(release)
+		if v := <-got; v != 3 {
+			t.Error("expected 3, got", v)
+		}
+	}
+	if n := overlaps.Load(); n != 0 {
+		t.Error("expected init calls never to overlap, got", n, "overlaps")
+	}
+}
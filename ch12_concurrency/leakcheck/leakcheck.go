@@ -0,0 +1,192 @@
+// Package leakcheck finds goroutines that outlive a test. It snapshots the
+// running goroutines when Check is called and, once the test finishes, fails
+// it with the stack trace of every goroutine that was started in between and
+// is still alive. It's a small in-repo take on go.uber.org/goleak.
+package leakcheck
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// defaultMaxWait is how long Check waits for goroutines to wind down on
+// their own before reporting them as leaked. Goroutines that were just told
+// to stop (closed channel, cancelled context) usually need a moment to exit.
+const defaultMaxWait = time.Second
+
+// ignoredTopFunctions are goroutines owned by the runtime or the testing
+// package that can come and go while a test runs.
+var ignoredTopFunctions = []string{
+	"testing.",
+	"runtime.goexit",
+	"runtime.ensureSigM",
+	"os/signal.signal_recv",
+	"os/signal.loop",
+}
+
+type config struct {
+	maxWait    time.Duration
+	ignoredTop []string
+}
+
+// Option changes how Check decides whether a goroutine leaked.
+type Option func(*config)
+
+// MaxWait sets how long to wait for goroutines to exit before failing.
+func MaxWait(d time.Duration) Option {
+	return func(c *config) {
+		c.maxWait = d
+	}
+}
+
+// IgnoreTopFunction ignores goroutines whose topmost stack frame is the
+// given function, e.g. "net/http.(*persistConn).readLoop".
+func IgnoreTopFunction(fn string) Option {
+	return func(c *config) {
+		c.ignoredTop = append(c.ignoredTop, fn)
+	}
+}
+
+// Check records the goroutines running right now and registers a cleanup on
+// t that fails the test if any goroutine started afterwards is still running
+// when the test ends. Call it first thing in the test:
+//
+//	func TestSomething(t *testing.T) {
+//		leakcheck.Check(t)
+//		...
+//	}
+func Check(t testing.TB, opts ...Option) {
+	t.Helper()
+	cfg := config{maxWait: defaultMaxWait}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	before := map[int]bool{}
+	for _, g := range snapshot() {
+		before[g.id] = true
+	}
+	t.Cleanup(func() {
+		leaked := waitForLeaks(before, cfg)
+		if len(leaked) == 0 {
+			return
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "found %d leaked goroutine(s):\n", len(leaked))
+		for _, g := range leaked {
+			sb.WriteString("\n")
+			sb.WriteString(g.stack)
+			sb.WriteString("\n")
+		}
+		t.Error(sb.String())
+	})
+}
+
+// waitForLeaks polls the running goroutines with a growing back-off until
+// none of them leaked or cfg.maxWait is used up.
+func waitForLeaks(before map[int]bool, cfg config) []goroutine {
+	deadline := time.Now().Add(cfg.maxWait)
+	delay := time.Millisecond
+	for {
+		leaked := findLeaks(before, cfg)
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+}
+
+func findLeaks(before map[int]bool, cfg config) []goroutine {
+	self := currentID()
+	var leaked []goroutine
+	for _, g := range snapshot() {
+		if g.id == self || before[g.id] || g.ignored(cfg.ignoredTop) {
+			continue
+		}
+		leaked = append(leaked, g)
+	}
+	return leaked
+}
+
+type goroutine struct {
+	id    int
+	top   string
+	stack string
+}
+
+func (g goroutine) ignored(extra []string) bool {
+	for _, prefix := range ignoredTopFunctions {
+		if strings.HasPrefix(g.top, prefix) {
+			return true
+		}
+	}
+	for _, fn := range extra {
+		if g.top == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot returns every goroutine in the process, as reported by
+// runtime.Stack. The dump is a list of blocks separated by blank lines:
+//
+//	goroutine 18 [chan receive]:
+//	main.worker(0xc000010000)
+//		/path/to/main.go:12 +0x30
+//	created by main.main in goroutine 1
+//		/path/to/main.go:20 +0x5c
+func snapshot() []goroutine {
+	var goroutines []goroutine
+	for _, block := range strings.Split(string(stacks(true)), "\n\n") {
+		g, ok := parse(block)
+		if ok {
+			goroutines = append(goroutines, g)
+		}
+	}
+	return goroutines
+}
+
+func currentID() int {
+	g, _ := parse(string(stacks(false)))
+	return g.id
+}
+
+func stacks(all bool) []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func parse(block string) (goroutine, bool) {
+	block = strings.TrimSpace(block)
+	header, rest, _ := strings.Cut(block, "\n")
+	if !strings.HasPrefix(header, "goroutine ") {
+		return goroutine{}, false
+	}
+	idStr, _, _ := strings.Cut(strings.TrimPrefix(header, "goroutine "), " ")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return goroutine{}, false
+	}
+	frame, _, _ := strings.Cut(rest, "\n")
+	if i := strings.LastIndexByte(frame, '('); i > 0 {
+		frame = frame[:i]
+	}
+	return goroutine{
+		id:    id,
+		top:   strings.TrimSpace(frame),
+		stack: block,
+	}, true
+}
@@ -0,0 +1,96 @@
+package leakcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder stands in for *testing.T so the tests can run the registered
+// cleanups themselves and inspect what would have been reported.
+type recorder struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) Error(args ...any) {
+	for _, a := range args {
+		r.errors = append(r.errors, a.(string))
+	}
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestCheckNoLeak(t *testing.T) {
+	r := &recorder{}
+	Check(r)
+	done := make(chan struct{})
+	go func() {
+		close(done)
+	}()
+	<-done
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Error("expected no leaks, got", r.errors)
+	}
+}
+
+func TestCheckWaitsForExitingGoroutines(t *testing.T) {
+	r := &recorder{}
+	Check(r)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+	}()
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Error("expected no leaks, got", r.errors)
+	}
+}
+
+func blockForever(ch chan struct{}) {
+	<-ch
+}
+
+func TestCheckReportsLeak(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	r := &recorder{}
+	Check(r, MaxWait(10*time.Millisecond))
+	go blockForever(release)
+	r.finish()
+
+	if len(r.errors) != 1 {
+		t.Fatal("expected one report, got", len(r.errors))
+	}
+	if !strings.Contains(r.errors[0], "leakcheck.blockForever") {
+		t.Error("expected stack of blockForever in report, got", r.errors[0])
+	}
+}
+
+func TestCheckIgnoreTopFunction(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	r := &recorder{}
+	Check(r,
+		MaxWait(10*time.Millisecond),
+		IgnoreTopFunction("ch12_concurrency/leakcheck.blockForever"))
+	go blockForever(release)
+	r.finish()
+
+	if len(r.errors) != 0 {
+		t.Error("expected ignored goroutine not to be reported, got", r.errors)
+	}
+}
@@ -0,0 +1,235 @@
+// Package memo caches the results of an expensive function per key. Values
+// are computed on first request, concurrent requests for the same key share
+// a single computation and memory is capped by a sharded LRU.
+package memo
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultCapacity = 10_000
+	defaultShards   = 16
+)
+
+type config struct {
+	capacity int
+	shards   int
+}
+
+// Option changes how a Memo is set up.
+type Option func(*config)
+
+// WithCapacity caps the number of cached values. The cap is split evenly
+// across the shards, each of which evicts its least recently used value
+// once it's full.
+func WithCapacity(n int) Option {
+	return func(c *config) {
+		c.capacity = n
+	}
+}
+
+// WithShards sets how many independently locked shards the cache is split
+// into. More shards mean less lock contention.
+func WithShards(n int) Option {
+	return func(c *config) {
+		c.shards = n
+	}
+}
+
+// Metrics is a snapshot of what a Memo has been doing.
+type Metrics struct {
+	// Hits counts calls answered from the cache.
+	Hits uint64
+	// Misses counts calls that ran the function.
+	Misses uint64
+	// Shared counts calls that waited for a computation another caller
+	// had already started for the same key.
+	Shared uint64
+	// Errors counts computations that failed. Failures aren't cached.
+	Errors uint64
+	// Evictions counts values dropped to stay within the capacity.
+	Evictions uint64
+	// Entries is the number of values currently cached.
+	Entries int
+}
+
+// Memo memoizes fn. It's safe for concurrent use.
+type Memo[K comparable, V any] struct {
+	fn     func(K) (V, error)
+	hash   func(K) uint64
+	shards []*shard[K, V]
+
+	hits, misses, shared, errors, evictions atomic.Uint64
+}
+
+// New returns a Memo for fn. Keys are spread over the shards by hashing
+// their fmt "%v" representation. Use NewWithHash if that's too slow or not
+// unique for your key type.
+func New[K comparable, V any](fn func(K) (V, error), opts ...Option) *Memo[K, V] {
+	return NewWithHash(fn, func(key K) uint64 {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%v", key)
+		return h.Sum64()
+	}, opts...)
+}
+
+// NewWithHash returns a Memo for fn that uses hash to pick a key's shard.
+func NewWithHash[K comparable, V any](fn func(K) (V, error), hash func(K) uint64, opts ...Option) *Memo[K, V] {
+	cfg := config{capacity: defaultCapacity, shards: defaultShards}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	cfg.shards = max(cfg.shards, 1)
+	perShard := max((cfg.capacity+cfg.shards-1)/cfg.shards, 1)
+
+	m := &Memo[K, V]{
+		fn:     fn,
+		hash:   hash,
+		shards: make([]*shard[K, V], cfg.shards),
+	}
+	for i := range m.shards {
+		m.shards[i] = &shard[K, V]{
+			capacity: perShard,
+			items:    map[K]*list.Element{},
+			calls:    map[K]*call[V]{},
+			lru:      list.New(),
+		}
+	}
+	return m
+}
+
+// Get returns the value for key, computing it if it isn't cached. If another
+// caller is already computing it, Get waits for that result instead of
+// starting a second computation.
+func (m *Memo[K, V]) Get(key K) (V, error) {
+	s := m.shards[m.hash(key)%uint64(len(m.shards))]
+
+	s.mu.Lock()
+	if el, ok := s.items[key]; ok {
+		s.lru.MoveToFront(el)
+		val := el.Value.(*item[K, V]).val
+		s.mu.Unlock()
+		m.hits.Add(1)
+		return val, nil
+	}
+	if c, ok := s.calls[key]; ok {
+		s.mu.Unlock()
+		m.shared.Add(1)
+		<-c.done
+		return c.val, c.err
+	}
+	c := &call[V]{done: make(chan struct{})}
+	s.calls[key] = c
+	s.mu.Unlock()
+
+	m.misses.Add(1)
+	m.compute(s, key, c)
+	return c.val, c.err
+}
+
+// compute runs fn for c and caches its result. If fn panics, the callers
+// waiting for c get a PanicError and the panic goes on in this goroutine.
+func (m *Memo[K, V]) compute(s *shard[K, V], key K, c *call[V]) {
+	// recover returns nil if fn called runtime.Goexit, normalReturn tells
+	// that apart from fn returning
+	normalReturn := false
+	defer func() {
+		r := recover()
+		switch {
+		case r != nil:
+			c.err = &PanicError{Value: r}
+		case !normalReturn:
+			c.err = ErrGoexit
+		}
+		close(c.done)
+
+		s.mu.Lock()
+		delete(s.calls, key)
+		if c.err == nil {
+			if s.add(key, c.val) {
+				m.evictions.Add(1)
+			}
+		}
+		s.mu.Unlock()
+		if c.err != nil {
+			m.errors.Add(1)
+		}
+		if r != nil {
+			panic(r)
+		}
+	}()
+	c.val, c.err = m.fn(key)
+	normalReturn = true
+}
+
+// Metrics returns the current counters.
+func (m *Memo[K, V]) Metrics() Metrics {
+	entries := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		entries += s.lru.Len()
+		s.mu.Unlock()
+	}
+	return Metrics{
+		Hits:      m.hits.Load(),
+		Misses:    m.misses.Load(),
+		Shared:    m.shared.Load(),
+		Errors:    m.errors.Load(),
+		Evictions: m.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+// ErrGoexit is returned to the callers that waited for a computation whose
+// goroutine called runtime.Goexit, e.g. through t.FailNow.
+var ErrGoexit = errors.New("memo: computation called runtime.Goexit")
+
+// PanicError is returned to the callers that waited for a computation that
+// panicked. Like failures, panics aren't cached.
+type PanicError struct {
+	Value any
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("memo: computation panicked: %v", pe.Value)
+}
+
+// call is a computation in flight. done is closed once val and err are set.
+type call[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+}
+
+type item[K comparable, V any] struct {
+	key K
+	val V
+}
+
+type shard[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[K]*list.Element
+	calls    map[K]*call[V]
+	// lru holds *item, most recently used at the front
+	lru *list.List
+}
+
+// add stores val and reports whether another value had to be evicted to
+// make room for it. The caller must hold s.mu.
+func (s *shard[K, V]) add(key K, val V) bool {
+	s.items[key] = s.lru.PushFront(&item[K, V]{key: key, val: val})
+	if s.lru.Len() <= s.capacity {
+		return false
+	}
+	oldest := s.lru.Back()
+	s.lru.Remove(oldest)
+	delete(s.items, oldest.Value.(*item[K, V]).key)
+	return true
+}
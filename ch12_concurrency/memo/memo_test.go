@@ -0,0 +1,194 @@
+package memo
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetComputesOnce(t *testing.T) {
+	calls := 0
+	m := New(func(key int) (int, error) {
+		calls++
+		return key * key, nil
+	})
+	for i := 0; i < 3; i++ {
+		if v, err := m.Get(4); v != 16 || err != nil {
+			t.Error("expected 16 <nil>, got", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Error("expected 1 call, got", calls)
+	}
+	metrics := m.Metrics()
+	if metrics.Hits != 2 || metrics.Misses != 1 || metrics.Entries != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestGetSharesInFlightCalls(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := New(func(key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return "value for " + key, nil
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer wg.Done()
+			if v, _ := m.Get("a"); v != "value for a" {
+				t.Error("unexpected value", v)
+			}
+		}()
+	}
+	// give the callers a moment to pile up behind the first computation
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Error("expected 1 call, got", calls.Load())
+	}
+	if metrics := m.Metrics(); metrics.Misses+metrics.Shared+metrics.Hits != 10 {
+		t.Errorf("expected 10 calls in total, got %+v", metrics)
+	}
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	m := New(func(key int) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, boom
+		}
+		return key, nil
+	})
+	if _, err := m.Get(1); !errors.Is(err, boom) {
+		t.Error("expected boom, got", err)
+	}
+	if v, err := m.Get(1); v != 1 || err != nil {
+		t.Error("expected 1 <nil>, got", v, err)
+	}
+	if metrics := m.Metrics(); metrics.Errors != 1 {
+		t.Error("expected 1 error, got", metrics.Errors)
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	calls := map[int]int{}
+	m := NewWithHash(func(key int) (int, error) {
+		calls[key]++
+		return key, nil
+	}, func(key int) uint64 {
+		return uint64(key)
+	}, WithCapacity(2), WithShards(1))
+
+	m.Get(1)
+	m.Get(2)
+	m.Get(1) // 1 is now the most recently used
+	m.Get(3) // evicts 2
+	m.Get(1)
+	m.Get(2)
+
+	if calls[1] != 1 || calls[2] != 2 || calls[3] != 1 {
+		t.Error("unexpected calls", calls)
+	}
+	metrics := m.Metrics()
+	if metrics.Evictions != 2 || metrics.Entries != 2 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestGetPanicReleasesWaiters(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := New(func(key string) (string, error) {
+		if calls.Add(1) == 1 {
+			<-release
+			panic("boom")
+		}
+		return "value for " + key, nil
+	})
+
+	recovered := make(chan any)
+	go func() {
+		defer func() {
+			recovered <- recover()
+		}()
+		m.Get("a")
+	}()
+	// wait for the computation to start, so the others share it
+	for m.Metrics().Misses == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			defer wg.Done()
+			var pe *PanicError
+			if _, err := m.Get("a"); !errors.As(err, &pe) || pe.Value != "boom" {
+				t.Error("expected PanicError boom, got", err)
+			}
+		}()
+	}
+	for m.Metrics().Shared != 5 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if r := <-recovered; r != "boom" {
+		t.Error("expected the computing caller to panic with boom, got", r)
+	}
+	if v, err := m.Get("a"); v != "value for a" || err != nil {
+		t.Error("expected the panic not to be cached, got", v, err)
+	}
+}
+
+func TestGetGoexitIsNotCached(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := New(func(key string) (string, error) {
+		if calls.Add(1) == 1 {
+			<-release
+			runtime.Goexit()
+		}
+		return "value for " + key, nil
+	})
+
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		m.Get("a")
+	}()
+	for m.Metrics().Misses == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	waiter := make(chan error)
+	go func() {
+		_, err := m.Get("a")
+		waiter <- err
+	}()
+	for m.Metrics().Shared == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	<-exited
+
+	if err := <-waiter; !errors.Is(err, ErrGoexit) {
+		t.Error("expected ErrGoexit, got", err)
+	}
+	if v, err := m.Get("a"); v != "value for a" || err != nil {
+		t.Error("expected the Goexit not to be cached, got", v, err)
+	}
+}
@@ -60,10 +60,11 @@ func (p *abProcessor) wait(ctx context.Context) (cIn, error) {
 	return cData, nil
 }
 
-func getResultA(ctx context.Context, in string) (aOut, error) {
+// getResultA and getResultB are variables so tests can swap in slow stages.
+var getResultA = func(ctx context.Context, in string) (aOut, error) {
 	return aOut{}, nil
 }
 
-func getResultB(ctx context.Context, in string) (bOut, error) {
+var getResultB = func(ctx context.Context, in string) (bOut, error) {
 	return bOut{}, nil
 }
@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"time"
+
+	"ch12_concurrency/clock"
 )
 
 type Input struct {
@@ -17,8 +19,10 @@ type COut struct {
 	frequencyCount map[rune]int
 }
 
-func GatherAndProcess(ctx context.Context, data Input) (COut, error) {
-	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+// GatherAndProcess gives up if the processing takes longer than 50ms, as
+// measured by clk.
+func GatherAndProcess(ctx context.Context, clk clock.Clock, data Input) (COut, error) {
+	ctx, cancel := clock.WithTimeout(ctx, clk, 50*time.Millisecond)
 	defer cancel()
 
 	ab := newABProcessor()
@@ -45,7 +49,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	cOut, err := GatherAndProcess(context.Background(), Input{
+	cOut, err := GatherAndProcess(context.Background(), clock.Real, Input{
 		A: os.Args[1],
 		B: os.Args[2],
 	})
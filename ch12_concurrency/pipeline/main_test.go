@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ch12_concurrency/clock"
+	"ch12_concurrency/leakcheck"
+)
+
+func TestGatherAndProcess(t *testing.T) {
+	leakcheck.Check(t)
+	// the fake clock never reaches the deadline, however slow the machine
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	_, err := GatherAndProcess(context.Background(), fake, Input{A: "a", B: "b"})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestGatherAndProcessTimeout(t *testing.T) {
+	leakcheck.Check(t)
+	entered := make(chan struct{})
+	orig := getResultA
+	t.Cleanup(func() { getResultA = orig })
+	getResultA = func(ctx context.Context, in string) (aOut, error) {
+		close(entered)
+		<-ctx.Done()
+		return aOut{}, ctx.Err()
+	}
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	errs := make(chan error)
+	go func() {
+		_, err := GatherAndProcess(context.Background(), fake, Input{A: "a", B: "b"})
+		errs <- err
+	}()
+	<-entered
+	fake.Advance(50 * time.Millisecond)
+	if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", err)
+	}
+}
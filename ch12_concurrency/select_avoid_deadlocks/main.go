@@ -3,18 +3,30 @@ package main
 import "fmt"
 
 func main() {
+	v, v2 := exchange()
+	fmt.Println(v, v2)
+	// produces: 2 1, no deadlock!
+}
+
+func exchange() (int, int) {
 	ch1 := make(chan int)
 	ch2 := make(chan int)
+	// closing done tells the goroutine to give up on its read from
+	// channel 2 - without it the goroutine would stay blocked forever.
+	// This is the 'done channel pattern', see pg 299
+	done := make(chan struct{})
+	defer close(done)
 
 	go func() {
 		v := 1
 		// writes to channel 1
 		ch1 <- v
-		// reads from channel 2
-		v2 := <-ch2
-		// the line below is never executed, as main exists and kills this
-		// go routine. I need to 'clean up the goroutine' - see pg 299
-		fmt.Println("goroutine: ", v, v2)
+		// reads from channel 2, unless we are told to stop
+		select {
+		case v2 := <-ch2:
+			fmt.Println("goroutine: ", v, v2)
+		case <-done:
+		}
 	}()
 
 	v := 2
@@ -25,6 +37,5 @@ func main() {
 	case v2 = <-ch1: // reads from channel 1
 	}
 
-	fmt.Println(v, v2)
-	// produces: 2 1, no deadlock!
+	return v, v2
 }
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"ch12_concurrency/leakcheck"
+)
+
+func TestExchange(t *testing.T) {
+	leakcheck.Check(t)
+	v, v2 := exchange()
+	if v != 2 || v2 != 1 {
+		t.Errorf("expected 2 1, got %d %d", v, v2)
+	}
+}
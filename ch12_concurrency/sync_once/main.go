@@ -2,34 +2,37 @@ package main
 
 import (
 	"fmt"
-	"sync"
+
+	"ch12_concurrency/lazy"
 )
 
 func main() {
 	// initializing! will pint out only once
-	result := Parse("hello")
-	fmt.Println(result)
-	result = Parse("goodbye")
-	fmt.Println(result)
+	result, err := Parse("hello")
+	fmt.Println(result, err)
+	result, err = Parse("goodbye")
+	fmt.Println(result, err)
 }
 
 type SlowComplicatedParser interface {
 	Parse(string) string
 }
 
-var parser SlowComplicatedParser
-var once sync.Once
+// a sync.Once can't report a failed initialization and never runs again,
+// lazy.Lazy retries on the next call if initParser fails
+var parser = lazy.New(initParser)
 
-func Parse(dataToParse string) string {
-	once.Do(func() {
-		parser = initParser()
-	})
-	return parser.Parse(dataToParse)
+func Parse(dataToParse string) (string, error) {
+	p, err := parser.Get()
+	if err != nil {
+		return "", err
+	}
+	return p.Parse(dataToParse), nil
 }
 
-func initParser() SlowComplicatedParser {
+func initParser() (SlowComplicatedParser, error) {
 	fmt.Println("initializing!")
-	return SCPI{}
+	return SCPI{}, nil
 }
 
 type SCPI struct{}
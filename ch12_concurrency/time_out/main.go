@@ -6,35 +6,123 @@ import (
 	"fmt"
 	"math/rand"
 	"time"
+
+	"ch12_concurrency/clock"
 )
 
 func main() {
-	result, err := timeLimit(doSomeWork, 2*time.Second)
+	result, err := timeLimit(context.Background(), clock.Real, doSomeWork, 2*time.Second)
 	fmt.Println(result, err)
 
+	// start a second attempt if the first one hasn't answered after 500ms,
+	// the first attempt to finish wins
+	result, err = hedgedTimeLimit(context.Background(), clock.Real, doSomeWork, 2*time.Second, 500*time.Millisecond, 3)
+	fmt.Println(result, err, errors.Is(err, context.DeadlineExceeded))
+}
+
+// TimeoutError is returned when the worker didn't finish within Limit. It
+// unwraps to context.DeadlineExceeded, so callers can simply check
+// errors.Is(err, context.DeadlineExceeded).
+type TimeoutError struct {
+	Limit time.Duration
 }
 
-func timeLimit[T any](worker func() T, limit time.Duration) (T, error) {
-	out := make(chan T, 1)
-	ctx, cancel := context.WithTimeout(context.Background(), limit)
+func (te TimeoutError) Error() string {
+	return fmt.Sprintf("work timed out after %v", te.Limit)
+}
+
+func (te TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// timeLimit runs worker and waits at most limit, as measured by c, for its
+// result. The context handed to the worker is cancelled once timeLimit
+// returns, so a worker that respects it stops instead of running on in the
+// background.
+func timeLimit[T any](ctx context.Context, c clock.Clock, worker func(context.Context) (T, error), limit time.Duration) (T, error) {
+	return hedgedTimeLimit(ctx, c, worker, limit, 0, 1)
+}
+
+// hedgedTimeLimit works like timeLimit, but if no attempt has finished after
+// hedgeAfter it starts another one, up to maxAttempts in total. The first
+// attempt to succeed wins and all others are cancelled. If every attempt
+// fails, the error of the last one is returned. A maxAttempts below 1 counts
+// as 1.
+func hedgedTimeLimit[T any](ctx context.Context, c clock.Clock, worker func(context.Context) (T, error),
+	limit, hedgeAfter time.Duration, maxAttempts int) (T, error) {
+	var zero T
+	maxAttempts = max(maxAttempts, 1)
+	ctx, cancel := clock.WithTimeout(ctx, c, limit)
 	defer cancel()
-	go func() {
-		out <- worker()
-	}()
-	select {
-	case result := <-out:
-		return result, nil
-	case <-ctx.Done():
-		var zero T
-		return zero, errors.New("work timed out")
+
+	// ctxErr is what's returned once ctx is done, whether we noticed that
+	// ourselves or through an attempt that gave up because of it
+	ctxErr := func() error {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return TimeoutError{Limit: limit}
+		}
+		return ctx.Err()
+	}
+
+	type result struct {
+		val T
+		err error
+	}
+	// buffered, so attempts that finish after we returned don't block
+	out := make(chan result, maxAttempts)
+	attempt := func() {
+		go func() {
+			val, err := worker(ctx)
+			out <- result{val, err}
+		}()
+	}
+
+	var hedge <-chan time.Time
+	if hedgeAfter > 0 && maxAttempts > 1 {
+		ticker := c.NewTicker(hedgeAfter)
+		defer ticker.Stop()
+		hedge = ticker.C()
+	}
+
+	attempt()
+	started, failed := 1, 0
+	for {
+		select {
+		case r := <-out:
+			if r.err == nil {
+				return r.val, nil
+			}
+			if ctx.Err() != nil {
+				return zero, ctxErr()
+			}
+			failed++
+			if failed == maxAttempts {
+				return zero, r.err
+			}
+			if failed == started {
+				// nothing in flight anymore, don't wait for the next hedge
+				attempt()
+				started++
+			}
+		case <-hedge:
+			if started < maxAttempts {
+				attempt()
+				started++
+			}
+		case <-ctx.Done():
+			return zero, ctxErr()
+		}
 	}
 }
 
-func doSomeWork() int {
+func doSomeWork(ctx context.Context) (int, error) {
 	if x := rand.Int(); x%2 == 0 {
-		return x
-	} else {
-		time.Sleep(10 * time.Second)
-		return 100
+		return x, nil
+	}
+	select {
+	case <-time.After(10 * time.Second):
+		return 100, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
 	}
 }
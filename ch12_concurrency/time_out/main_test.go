@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ch12_concurrency/clock"
+	"ch12_concurrency/leakcheck"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTimeLimit(t *testing.T) {
+	leakcheck.Check(t)
+	result, err := timeLimit(context.Background(), clock.NewFake(epoch), func(context.Context) (int, error) {
+		return 42, nil
+	}, time.Second)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	if result != 42 {
+		t.Error("expected 42, got", result)
+	}
+}
+
+func TestTimeLimitTimeout(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(epoch)
+	go func() {
+		fake.BlockUntil(1)
+		fake.Advance(10 * time.Millisecond)
+	}()
+	_, err := timeLimit(context.Background(), fake, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", err)
+	}
+	var te TimeoutError
+	if !errors.As(err, &te) || te.Limit != 10*time.Millisecond {
+		t.Error("expected TimeoutError with limit 10ms, got", err)
+	}
+}
+
+func TestTimeLimitTimeoutWorkerFinishesFirst(t *testing.T) {
+	leakcheck.Check(t)
+	// the worker's error and ctx.Done are ready at the same time, select
+	// picks either, so try often enough to hit both
+	for range 50 {
+		fake := clock.NewFake(epoch)
+		go func() {
+			fake.BlockUntil(1)
+			fake.Advance(10 * time.Millisecond)
+		}()
+		_, err := timeLimit(context.Background(), fake, func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}, 10*time.Millisecond)
+		var te TimeoutError
+		if !errors.As(err, &te) {
+			t.Fatal("expected TimeoutError, got", err)
+		}
+	}
+}
+
+func TestTimeLimitParentCancelled(t *testing.T) {
+	leakcheck.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := timeLimit(ctx, clock.NewFake(epoch), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Error("expected context.Canceled, got", err)
+	}
+}
+
+func TestTimeLimitWorkerError(t *testing.T) {
+	leakcheck.Check(t)
+	boom := errors.New("boom")
+	_, err := timeLimit(context.Background(), clock.NewFake(epoch), func(context.Context) (int, error) {
+		return 0, boom
+	}, time.Second)
+	if !errors.Is(err, boom) {
+		t.Error("expected boom, got", err)
+	}
+}
+
+func TestHedgedTimeLimit(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(epoch)
+	go func() {
+		// the deadline and the hedge ticker
+		fake.BlockUntil(2)
+		fake.Advance(10 * time.Millisecond)
+	}()
+	var attempts atomic.Int32
+	result, err := hedgedTimeLimit(context.Background(), fake, func(ctx context.Context) (int, error) {
+		n := attempts.Add(1)
+		if n == 1 {
+			// the first attempt hangs until it's cancelled
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return int(n), nil
+	}, time.Second, 10*time.Millisecond, 3)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	if result != 2 {
+		t.Error("expected the second attempt to win, got", result)
+	}
+}
+
+func TestHedgedTimeLimitRetriesFailures(t *testing.T) {
+	leakcheck.Check(t)
+	var attempts atomic.Int32
+	result, err := hedgedTimeLimit(context.Background(), clock.NewFake(epoch), func(ctx context.Context) (int, error) {
+		if n := attempts.Add(1); n < 3 {
+			return 0, errors.New("flaky")
+		}
+		return 3, nil
+	}, time.Second, time.Hour, 3)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	if result != 3 {
+		t.Error("expected 3, got", result)
+	}
+}
+
+func TestHedgedTimeLimitNoAttempts(t *testing.T) {
+	leakcheck.Check(t)
+	boom := errors.New("boom")
+	for _, maxAttempts := range []int{0, -1} {
+		var attempts atomic.Int32
+		_, err := hedgedTimeLimit(context.Background(), clock.NewFake(epoch), func(context.Context) (int, error) {
+			attempts.Add(1)
+			return 0, boom
+		}, time.Second, time.Millisecond, maxAttempts)
+		if !errors.Is(err, boom) {
+			t.Error("expected boom, got", err)
+		}
+		if n := attempts.Load(); n != 1 {
+			t.Errorf("expected maxAttempts %d to make 1 attempt, got %d", maxAttempts, n)
+		}
+	}
+}
+
+func TestHedgedTimeLimitTimeout(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(epoch)
+	started := make(chan struct{}, 3)
+	go func() {
+		<-started
+		fake.BlockUntil(2)
+		// step through the hedges one at a time, a tick the loop hasn't
+		// taken yet would swallow the next one
+		for range 2 {
+			fake.Advance(300 * time.Millisecond)
+			<-started
+		}
+		fake.Advance(400 * time.Millisecond)
+	}()
+	var attempts atomic.Int32
+	_, err := hedgedTimeLimit(context.Background(), fake, func(ctx context.Context) (int, error) {
+		attempts.Add(1)
+		started <- struct{}{}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, time.Second, 300*time.Millisecond, 3)
+	var te TimeoutError
+	if !errors.As(err, &te) || te.Limit != time.Second {
+		t.Error("expected TimeoutError with limit 1s, got", err)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Error("expected 3 attempts, got", n)
+	}
+}
+
+// leakRecorder stands in for *testing.T, so a test can look at what
+// leakcheck.Check would report instead of failing.
+type leakRecorder struct {
+	testing.TB
+	cleanups []func()
+	errors   []any
+}
+
+func (r *leakRecorder) Helper() {}
+
+func (r *leakRecorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *leakRecorder) Error(args ...any) {
+	r.errors = append(r.errors, args...)
+}
+
+func (r *leakRecorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestTimeLimitTimeoutStopsWorker(t *testing.T) {
+	r := &leakRecorder{}
+	leakcheck.Check(r)
+	fake := clock.NewFake(epoch)
+	go func() {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+	}()
+	seen := make(chan error, 1)
+	_, err := timeLimit(context.Background(), fake, func(ctx context.Context) (int, error) {
+		// still blocked when timeLimit gives up
+		<-ctx.Done()
+		seen <- ctx.Err()
+		return 0, ctx.Err()
+	}, time.Second)
+	var te TimeoutError
+	if !errors.As(err, &te) {
+		t.Error("expected TimeoutError, got", err)
+	}
+	if err := <-seen; !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected the worker to see context.DeadlineExceeded, got", err)
+	}
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Error("expected no leaks, got", r.errors)
+	}
+}
+
+func TestTimeLimitTimeoutReportsStuckWorker(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	r := &leakRecorder{}
+	leakcheck.Check(r, leakcheck.MaxWait(10*time.Millisecond))
+	fake := clock.NewFake(epoch)
+	go func() {
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+	}()
+	_, err := timeLimit(context.Background(), fake, func(context.Context) (int, error) {
+		// ignores the cancellation
+		<-release
+		return 0, nil
+	}, time.Second)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", err)
+	}
+	r.finish()
+	if len(r.errors) != 1 {
+		t.Error("expected the stuck worker to be reported, got", r.errors)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"ch12_concurrency/leakcheck"
+)
+
+func TestProcessAndGather(t *testing.T) {
+	leakcheck.Check(t)
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 10; i++ {
+			ch <- i
+		}
+	}()
+	result := processAndGather(ch, func(i int) int {
+		return i * 2
+	}, 3)
+	slices.Sort(result)
+	expected := []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}
+	if !slices.Equal(expected, result) {
+		t.Error("expected", expected, "got", result)
+	}
+}
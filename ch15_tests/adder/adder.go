@@ -1,5 +1,74 @@
+// Package adder does integer arithmetic that reports overflow instead of
+// silently wrapping around.
 package adder
 
+import (
+	"errors"
+	"fmt"
+)
+
+// Integer is any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// ErrOverflow is wrapped by the errors of Add, Sub and Mul when the result
+// doesn't fit the type.
+var ErrOverflow = errors.New("integer overflow")
+
+func overflow[T Integer](x T, op string, y T) error {
+	return fmt.Errorf("%w: %v %s %v", ErrOverflow, x, op, y)
+}
+
+// signed reports whether T is a signed type.
+func signed[T Integer]() bool {
+	var zero T
+	return ^zero < 0
+}
+
+// Add returns x + y, or 0 and an error wrapping ErrOverflow if the sum
+// doesn't fit T.
+func Add[T Integer](x, y T) (T, error) {
+	sum := x + y
+	if (y > 0 && sum < x) || (y < 0 && sum > x) {
+		return 0, overflow(x, "+", y)
+	}
+	return sum, nil
+}
+
+// Sub returns x - y, or 0 and an error wrapping ErrOverflow if the
+// difference doesn't fit T.
+func Sub[T Integer](x, y T) (T, error) {
+	diff := x - y
+	if (y > 0 && diff > x) || (y < 0 && diff < x) {
+		return 0, overflow(x, "-", y)
+	}
+	return diff, nil
+}
+
+// Mul returns x * y, or 0 and an error wrapping ErrOverflow if the product
+// doesn't fit T.
+func Mul[T Integer](x, y T) (T, error) {
+	if x == 0 || y == 0 {
+		return 0, nil
+	}
+	if signed[T]() {
+		// the smallest value times -1 wraps around to itself, which the
+		// division below can't tell, it's the only value besides 0 that is
+		// its own negation
+		minusOne := ^T(0)
+		if (x == minusOne && y == -y) || (y == minusOne && x == -x) {
+			return 0, overflow(x, "*", y)
+		}
+	}
+	product := x * y
+	if product/y != x {
+		return 0, overflow(x, "*", y)
+	}
+	return product, nil
+}
+
 func addNumbers(x, y int) int {
-	return x + x
+	return x + y
 }
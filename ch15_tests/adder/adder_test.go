@@ -1,6 +1,12 @@
 package adder
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
 
 func Test_addNumbers(t *testing.T) {
 	result := addNumbers(2, 3)
@@ -8,3 +14,216 @@ func Test_addNumbers(t *testing.T) {
 		t.Error("incorrect result: expected 5, got", result)
 	}
 }
+
+type testCase[T Integer] struct {
+	name     string
+	x, y     T
+	want     T
+	overflow bool
+}
+
+func runTable[T Integer](t *testing.T, op func(T, T) (T, error), tests []testCase[T]) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := op(tt.x, tt.y)
+			if tt.overflow {
+				if !errors.Is(err, ErrOverflow) {
+					t.Errorf("got %v, %v, want ErrOverflow", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	runTable(t, Add[int], []testCase[int]{
+		{"small", 2, 3, 5, false},
+		{"negative", -2, -3, -5, false},
+		{"mixed", math.MaxInt, math.MinInt, -1, false},
+		{"max", math.MaxInt, 1, 0, true},
+		{"min", math.MinInt, -1, 0, true},
+	})
+	runTable(t, Add[int8], []testCase[int8]{
+		{"up to max", 100, 27, 127, false},
+		{"past max", 100, 28, 0, true},
+		{"down to min", -100, -28, -128, false},
+		{"past min", -100, -29, 0, true},
+	})
+	runTable(t, Add[uint8], []testCase[uint8]{
+		{"up to max", 200, 55, 255, false},
+		{"past max", 200, 56, 0, true},
+	})
+	runTable(t, Add[uint64], []testCase[uint64]{
+		{"max", math.MaxUint64, 0, math.MaxUint64, false},
+		{"past max", math.MaxUint64, 1, 0, true},
+	})
+}
+
+func TestSub(t *testing.T) {
+	runTable(t, Sub[int], []testCase[int]{
+		{"small", 2, 3, -1, false},
+		{"min minus -1", math.MinInt, -1, math.MinInt + 1, false},
+		{"past min", math.MinInt, 1, 0, true},
+		{"past max", math.MaxInt, -1, 0, true},
+		{"0 minus min", 0, math.MinInt, 0, true},
+	})
+	runTable(t, Sub[int16], []testCase[int16]{
+		{"down to min", -32000, 768, math.MinInt16, false},
+		{"past min", -32000, 769, 0, true},
+	})
+	runTable(t, Sub[uint], []testCase[uint]{
+		{"to zero", 3, 3, 0, false},
+		{"below zero", 2, 3, 0, true},
+	})
+}
+
+func TestMul(t *testing.T) {
+	runTable(t, Mul[int], []testCase[int]{
+		{"small", 6, 7, 42, false},
+		{"zero", math.MaxInt, 0, 0, false},
+		{"min times 1", math.MinInt, 1, math.MinInt, false},
+		{"min times -1", math.MinInt, -1, 0, true},
+		{"-1 times min", -1, math.MinInt, 0, true},
+		{"max times -1", math.MaxInt, -1, -math.MaxInt, false},
+		{"past max", math.MaxInt/2 + 1, 2, 0, true},
+	})
+	runTable(t, Mul[int8], []testCase[int8]{
+		{"down to min", -64, 2, -128, false},
+		{"past max", 64, 2, 0, true},
+		{"negative times negative", -16, -8, 0, true},
+		{"min times -1", -128, -1, 0, true},
+	})
+	runTable(t, Mul[uint32], []testCase[uint32]{
+		{"max", 65535, 65537, math.MaxUint32, false},
+		{"past max", 65536, 65536, 0, true},
+	})
+}
+
+// checkBig compares the result of an operation with the exact result: it
+// must be right if that fits T and an overflow otherwise.
+func checkBig[T Integer](got T, err error, exact *big.Int, lo, hi *big.Int) bool {
+	fits := exact.Cmp(lo) >= 0 && exact.Cmp(hi) <= 0
+	if !fits {
+		return errors.Is(err, ErrOverflow)
+	}
+	return err == nil && toBig(got).Cmp(exact) == 0
+}
+
+func toBig[T Integer](v T) *big.Int {
+	if v < 0 {
+		return big.NewInt(int64(v))
+	}
+	return new(big.Int).SetUint64(uint64(v))
+}
+
+var (
+	minInt64  = big.NewInt(math.MinInt64)
+	maxInt64  = big.NewInt(math.MaxInt64)
+	zero      = big.NewInt(0)
+	maxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+func TestProperties(t *testing.T) {
+	properties := map[string]any{
+		"Add is commutative": func(x, y int32) bool {
+			a, errA := Add(x, y)
+			b, errB := Add(y, x)
+			return a == b && (errA == nil) == (errB == nil)
+		},
+		"Mul is commutative": func(x, y int16) bool {
+			a, errA := Mul(x, y)
+			b, errB := Mul(y, x)
+			return a == b && (errA == nil) == (errB == nil)
+		},
+		"0 is the identity of Add": func(x int64) bool {
+			got, err := Add(x, 0)
+			return err == nil && got == x
+		},
+		"1 is the identity of Mul": func(x uint64) bool {
+			got, err := Mul(x, 1)
+			return err == nil && got == x
+		},
+		"Sub undoes Add": func(x, y int8) bool {
+			sum, err := Add(x, y)
+			if err != nil {
+				return true
+			}
+			back, err := Sub(sum, y)
+			return err == nil && back == x
+		},
+		"Add matches big.Int": func(x, y int64) bool {
+			got, err := Add(x, y)
+			return checkBig(got, err, new(big.Int).Add(toBig(x), toBig(y)), minInt64, maxInt64)
+		},
+		"Mul matches big.Int": func(x, y uint64) bool {
+			got, err := Mul(x, y)
+			return checkBig(got, err, new(big.Int).Mul(toBig(x), toBig(y)), zero, maxUint64)
+		},
+	}
+	for name, f := range properties {
+		t.Run(name, func(t *testing.T) {
+			if err := quick.Check(f, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// seeds are the values overflow bugs hide around
+var seeds = [][2]int64{
+	{0, 0}, {1, -1}, {2, 3}, {math.MaxInt64, 1}, {math.MinInt64, -1},
+	{math.MinInt64, 1}, {math.MaxInt64, math.MinInt64}, {1 << 32, 1 << 31},
+}
+
+func FuzzAdd(f *testing.F) {
+	for _, s := range seeds {
+		f.Add(s[0], s[1])
+	}
+	f.Fuzz(func(t *testing.T, x, y int64) {
+		got, err := Add(x, y)
+		if !checkBig(got, err, new(big.Int).Add(toBig(x), toBig(y)), minInt64, maxInt64) {
+			t.Errorf("Add(%d, %d) = %d, %v", x, y, got, err)
+		}
+		got8, err := Add(uint8(x), uint8(y))
+		if !checkBig(got8, err, new(big.Int).Add(toBig(uint8(x)), toBig(uint8(y))), zero, big.NewInt(math.MaxUint8)) {
+			t.Errorf("Add(uint8(%d), uint8(%d)) = %d, %v", uint8(x), uint8(y), got8, err)
+		}
+	})
+}
+
+func FuzzSub(f *testing.F) {
+	for _, s := range seeds {
+		f.Add(s[0], s[1])
+	}
+	f.Fuzz(func(t *testing.T, x, y int64) {
+		got, err := Sub(x, y)
+		if !checkBig(got, err, new(big.Int).Sub(toBig(x), toBig(y)), minInt64, maxInt64) {
+			t.Errorf("Sub(%d, %d) = %d, %v", x, y, got, err)
+		}
+		gotU, err := Sub(uint64(x), uint64(y))
+		if !checkBig(gotU, err, new(big.Int).Sub(toBig(uint64(x)), toBig(uint64(y))), zero, maxUint64) {
+			t.Errorf("Sub(uint64(%d), uint64(%d)) = %d, %v", uint64(x), uint64(y), gotU, err)
+		}
+	})
+}
+
+func FuzzMul(f *testing.F) {
+	for _, s := range seeds {
+		f.Add(s[0], s[1])
+	}
+	f.Fuzz(func(t *testing.T, x, y int64) {
+		got, err := Mul(x, y)
+		if !checkBig(got, err, new(big.Int).Mul(toBig(x), toBig(y)), minInt64, maxInt64) {
+			t.Errorf("Mul(%d, %d) = %d, %v", x, y, got, err)
+		}
+		got8, err := Mul(int8(x), int8(y))
+		if !checkBig(got8, err, new(big.Int).Mul(toBig(int8(x)), toBig(int8(y))), big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)) {
+			t.Errorf("Mul(int8(%d), int8(%d)) = %d, %v", int8(x), int8(y), got8, err)
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package cmp
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type Person struct {
 	Name      string
@@ -8,10 +11,51 @@ type Person struct {
 	DateAdded time.Time
 }
 
-func CreatePerson(name string, age int) Person {
+// Clock tells CreatePerson what time it is, tests pass a FakeClock. It's
+// the Now part of the clock package of ch12_concurrency, which lives in
+// another module this one doesn't depend on.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the clock of the time package.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock that stands still until Advance moves it. It's safe
+// for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a fake clock showing start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// Advance moves the clock forward by d.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+}
+
+func CreatePerson(c Clock, name string, age int) Person {
 	return Person{
 		Name:      name,
 		Age:       age,
-		DateAdded: time.Now(),
+		DateAdded: c.Now(),
 	}
 }
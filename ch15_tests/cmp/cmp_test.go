@@ -3,14 +3,29 @@ package cmp
 import (
 	"github.com/google/go-cmp/cmp"
 	"testing"
+	"time"
 )
 
 func TestCreatePerson(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(now)
 	expected := Person{
-		Name: "Dennis",
-		Age:  37,
+		Name:      "Dennis",
+		Age:       37,
+		DateAdded: now,
+	}
+	result := CreatePerson(clock, "Dennis", 37)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Error(diff)
+	}
+
+	clock.Advance(time.Hour)
+	expected = Person{
+		Name:      "Ken",
+		Age:       81,
+		DateAdded: now.Add(time.Hour),
 	}
-	result := CreatePerson("Dennis", 37)
+	result = CreatePerson(clock, "Ken", 81)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Error(diff)
 	}
@@ -21,7 +36,7 @@ func TestCreatePersonIgnoreDate(t *testing.T) {
 		Name: "Dennis",
 		Age:  37,
 	}
-	result := CreatePerson("Dennis", 37)
+	result := CreatePerson(RealClock, "Dennis", 37)
 
 	comparer := cmp.Comparer(func(x, y Person) bool {
 		return x.Name == y.Name && x.Age == y.Age
@@ -2,4 +2,4 @@ module ch15
 
 go 1.22.5
 
-require github.com/google/go-cmp v0.6.0 // indirect
+require github.com/google/go-cmp v0.6.0
@@ -0,0 +1,109 @@
+// Package golden compares test output with golden files, the expected
+// output kept in testdata/<name>.golden. After an intended change of the
+// output the files are rewritten by running the tests with -update:
+//
+//	go test ./text -update
+//
+// The flag is only known to test binaries that import this package, so
+// name the packages instead of using ./... when updating.
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// dir is where the golden files are, relative to the package being tested.
+var dir = "testdata"
+
+// Normalizer rewrites the parts of an output that change from run to run,
+// like times and temporary paths, into something stable.
+type Normalizer func([]byte) []byte
+
+// Replace replaces every match of re with repl, which can refer to
+// submatches like regexp.ReplaceAll.
+func Replace(re *regexp.Regexp, repl string) Normalizer {
+	return func(b []byte) []byte {
+		return re.ReplaceAll(b, []byte(repl))
+	}
+}
+
+var timestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z| ?[+-]\d{2}:?\d{2})?( [A-Z]{3,4})?( m=[+-]\d+\.\d+)?`)
+
+// Timestamps replaces times formatted as RFC 3339 or by time.Time.String
+// with <TIME>.
+func Timestamps() Normalizer {
+	return Replace(timestamp, "<TIME>")
+}
+
+// Path replaces path with placeholder, with either kind of slashes. Use it
+// for directories like t.TempDir().
+func Path(path, placeholder string) Normalizer {
+	return func(b []byte) []byte {
+		b = bytes.ReplaceAll(b, []byte(path), []byte(placeholder))
+		if slashed := filepath.ToSlash(path); slashed != path {
+			b = bytes.ReplaceAll(b, []byte(slashed), []byte(placeholder))
+		}
+		return b
+	}
+}
+
+// Assert fails t if got, after the normalizers and turning CRLF into LF,
+// differs from testdata/<name>.golden, showing the difference line by line.
+// With -update it writes got to the file instead.
+func Assert(t testing.TB, name string, got []byte, normalizers ...Normalizer) {
+	t.Helper()
+	got = bytes.ReplaceAll(got, []byte("\r\n"), []byte("\n"))
+	for _, n := range normalizers {
+		got = n(got)
+	}
+
+	path := filepath.Join(dir, name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Log("updated", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("%s doesn't exist, run the test with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n"))
+	if diff := cmp.Diff(lines(want), lines(got)); diff != "" {
+		t.Errorf("output differs from %s (-want +got), run the test with -update if that's intended:\n%s", path, diff)
+	}
+}
+
+// AssertString is Assert for strings.
+func AssertString(t testing.TB, name, got string, normalizers ...Normalizer) {
+	t.Helper()
+	Assert(t, name, []byte(got), normalizers...)
+}
+
+func lines(b []byte) []string {
+	l := strings.SplitAfter(string(b), "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
@@ -0,0 +1,115 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder stands in for *testing.T so the tests can inspect what would
+// have been reported.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper()           {}
+func (r *recorder) Log(args ...any)   {}
+func (r *recorder) Fatal(args ...any) { r.Fatalf("%s", fmt.Sprint(args...)) }
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf doesn't stop the goroutine like the real one, Assert returns
+// right after calling it anyway.
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+// useDir points the golden files to a temporary directory for the test.
+func useDir(t *testing.T) string {
+	old := dir
+	dir = t.TempDir()
+	t.Cleanup(func() { dir = old })
+	return dir
+}
+
+func setUpdate(t *testing.T, v bool) {
+	old := *update
+	*update = v
+	t.Cleanup(func() { *update = old })
+}
+
+func TestAssert(t *testing.T) {
+	d := useDir(t)
+	if err := os.WriteFile(filepath.Join(d, "out.golden"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{}
+	Assert(r, "out", []byte("one\r\ntwo\r\nthree\r\n"))
+	if len(r.errors) != 0 {
+		t.Errorf("same output with CRLF failed: %v", r.errors)
+	}
+
+	r = &recorder{}
+	Assert(r, "out", []byte("one\n2\nthree\n"))
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], `"two\n"`) || !strings.Contains(r.errors[0], `"2\n"`) {
+		t.Errorf("diff not reported: %v", r.errors)
+	}
+
+	r = &recorder{}
+	Assert(r, "missing", []byte("x"))
+	if !r.fatal || !strings.Contains(r.errors[0], "-update") {
+		t.Errorf("missing file: %v", r.errors)
+	}
+}
+
+func TestAssertUpdate(t *testing.T) {
+	d := useDir(t)
+	setUpdate(t, true)
+
+	r := &recorder{}
+	Assert(r, "sub/new", []byte("created at 2024-05-01T12:00:00Z\n"), Timestamps())
+	if len(r.errors) != 0 {
+		t.Fatal(r.errors)
+	}
+	b, err := os.ReadFile(filepath.Join(d, "sub", "new.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "created at <TIME>\n" {
+		t.Errorf("wrote %q", b)
+	}
+}
+
+func TestNormalizers(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 45, 123456789, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		name       string
+		in         string
+		normalizer Normalizer
+		want       string
+	}{
+		{"RFC 3339", "at " + now.Format(time.RFC3339) + ".", Timestamps(), "at <TIME>."},
+		{"RFC 3339 nano", now.UTC().Format(time.RFC3339Nano), Timestamps(), "<TIME>"},
+		{"time.Time.String", "added " + now.String() + " ok", Timestamps(), "added <TIME> ok"},
+		{"monotonic", "t=" + time.Now().String(), Timestamps(), "t=<TIME>"},
+		{"path", "/tmp/TestX123/a.txt and /tmp/TestX123/b", Path("/tmp/TestX123", "<DIR>"), "<DIR>/a.txt and <DIR>/b"},
+		{"replace", "took 1.25s", Replace(regexp.MustCompile(`\d+(\.\d+)?m?s`), "<DURATION>"), "took <DURATION>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.normalizer([]byte(tt.in))); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,116 @@
+// Package text computes statistics of text in a single streaming pass, so
+// inputs larger than memory work too.
 package text
 
 import (
+	"io"
 	"os"
+	"unicode"
 	"unicode/utf8"
 )
 
+// Counts are the statistics of a text.
+type Counts struct {
+	Bytes int64
+	// Runes counts every byte that isn't part of valid UTF-8 as one rune,
+	// like utf8.RuneCount does.
+	Runes int64
+	// Lines is the number of newlines, a last line without one isn't
+	// counted, like wc does.
+	Lines int64
+	// Words are separated by white space.
+	Words int64
+	// InvalidUTF8 is the number of bytes that aren't part of valid UTF-8.
+	InvalidUTF8 int64
+	// LongestLine is the length in runes of the longest line, without its
+	// newline.
+	LongestLine int64
+}
+
+// Add adds o to c, for totals over several texts. The longest line is the
+// longer of both.
+func (c *Counts) Add(o Counts) {
+	c.Bytes += o.Bytes
+	c.Runes += o.Runes
+	c.Lines += o.Lines
+	c.Words += o.Words
+	c.InvalidUTF8 += o.InvalidUTF8
+	c.LongestLine = max(c.LongestLine, o.LongestLine)
+}
+
+// bufSize is how much Stats reads at once, the memory it needs doesn't
+// depend on the size of the input.
+const bufSize = 64 << 10
+
+// Stats reads r to the end and counts what's in it. On a read error the
+// counts up to the error are returned with it.
+func Stats(r io.Reader) (Counts, error) {
+	var c Counts
+	var inWord bool
+	var lineLen int64
+
+	// room for the start of a rune that was cut off at the end of the
+	// previous read
+	buf := make([]byte, utf8.UTFMax+bufSize)
+	pending := 0
+	for {
+		n, err := r.Read(buf[pending : pending+bufSize])
+		c.Bytes += int64(n)
+		data := buf[:pending+n]
+		atEOF := err == io.EOF
+
+		i := 0
+		for i < len(data) {
+			if !atEOF && !utf8.FullRune(data[i:]) {
+				break
+			}
+			ru, size := utf8.DecodeRune(data[i:])
+			i += size
+
+			c.Runes++
+			if ru == utf8.RuneError && size == 1 {
+				c.InvalidUTF8++
+			}
+			if ru == '\n' {
+				c.Lines++
+				c.LongestLine = max(c.LongestLine, lineLen)
+				lineLen = 0
+			} else {
+				lineLen++
+			}
+			if unicode.IsSpace(ru) {
+				inWord = false
+			} else if !inWord {
+				inWord = true
+				c.Words++
+			}
+		}
+		pending = copy(buf, data[i:])
+
+		if atEOF {
+			c.LongestLine = max(c.LongestLine, lineLen)
+			return c, nil
+		}
+		if err != nil {
+			return c, err
+		}
+	}
+}
+
+// StatsFile is Stats for the file named fileName.
+func StatsFile(fileName string) (Counts, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return Counts{}, err
+	}
+	defer f.Close()
+	return Stats(f)
+}
+
 func CountCharacters(fileName string) (int, error) {
-	data, err := os.ReadFile(fileName)
+	c, err := StatsFile(fileName)
 	if err != nil {
 		return 0, err
 	}
-	return utf8.RuneCount(data), nil
+	return int(c.Runes), nil
 }
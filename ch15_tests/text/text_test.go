@@ -1,17 +1,151 @@
 package text
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"ch15/golden"
+)
 
 func TestCountCharacters(t *testing.T) {
 	total, err := CountCharacters("testdata/sample1.txt")
 	if err != nil {
 		t.Error("Unexpected error:", err)
 	}
-	if total != 35 {
-		t.Error("Expected 35, got", total)
-	}
+	golden.AssertString(t, "sample1_characters", fmt.Sprintln(total))
 	_, err = CountCharacters("testdata/no_file.txt")
 	if err == nil {
 		t.Error("Expected an error")
 	}
 }
+
+func TestStatsFileGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".txt")
+		t.Run(name, func(t *testing.T) {
+			c, err := StatsFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden.AssertString(t, name+"_stats", fmt.Sprintf(
+				"bytes: %d\nrunes: %d\nlines: %d\nwords: %d\ninvalid: %d\nlongest: %d\n",
+				c.Bytes, c.Runes, c.Lines, c.Words, c.InvalidUTF8, c.LongestLine))
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Counts
+	}{
+		{"empty", "", Counts{}},
+		{"no newline", "hello world", Counts{Bytes: 11, Runes: 11, Words: 2, LongestLine: 11}},
+		{"lines", "one\ntwo three\n\nfour\n", Counts{Bytes: 20, Runes: 20, Lines: 4, Words: 4, LongestLine: 9}},
+		{"multi-byte", "¿Sabes cómo?\n", Counts{Bytes: 15, Runes: 13, Lines: 1, Words: 2, LongestLine: 12}},
+		{"unicode spaces", "a b c", Counts{Bytes: 8, Runes: 5, Words: 3, LongestLine: 5}},
+		{"invalid", "a\xffb\xc3", Counts{Bytes: 4, Runes: 4, Words: 1, InvalidUTF8: 2, LongestLine: 4}},
+		{"surrogate", "\xed\xa0\x80", Counts{Bytes: 3, Runes: 3, Words: 1, InvalidUTF8: 3, LongestLine: 3}},
+	}
+	readers := map[string]func(string) io.Reader{
+		"whole":    func(s string) io.Reader { return strings.NewReader(s) },
+		"one byte": func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"with EOF": func(s string) io.Reader { return iotest.DataErrReader(strings.NewReader(s)) },
+	}
+	for _, tt := range tests {
+		for name, reader := range readers {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				got, err := Stats(reader(tt.in))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("got %+v, want %+v", got, tt.want)
+				}
+				if runes := int64(utf8.RuneCountInString(tt.in)); got.Runes != runes {
+					t.Errorf("Runes = %d, utf8.RuneCount = %d", got.Runes, runes)
+				}
+			})
+		}
+	}
+}
+
+// TestStatsLarge feeds more than one buffer with runes cut at the buffer
+// boundaries.
+func TestStatsLarge(t *testing.T) {
+	line := strings.Repeat("é", 1000) + " x\n" // 2003 bytes
+	const lines = 200
+	got, err := Stats(io.LimitReader(&repeatReader{s: line}, int64(len(line)*lines)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Counts{
+		Bytes:       int64(len(line) * lines),
+		Runes:       1003 * lines,
+		Lines:       lines,
+		Words:       2 * lines,
+		LongestLine: 1002,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// repeatReader returns s over and over.
+type repeatReader struct {
+	s   string
+	off int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.s[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.s)
+	}
+	return n, nil
+}
+
+func TestStatsError(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("two words\n"), iotest.ErrReader(boom))
+	got, err := Stats(r)
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want boom", err)
+	}
+	if got.Words != 2 || got.Lines != 1 {
+		t.Errorf("counts before the error: %+v", got)
+	}
+}
+
+func TestCountsAdd(t *testing.T) {
+	total := Counts{Bytes: 1, Runes: 1, Lines: 1, Words: 1, InvalidUTF8: 1, LongestLine: 5}
+	total.Add(Counts{Bytes: 2, Runes: 2, Lines: 2, Words: 2, InvalidUTF8: 2, LongestLine: 3})
+	want := Counts{Bytes: 3, Runes: 3, Lines: 3, Words: 3, InvalidUTF8: 3, LongestLine: 5}
+	if total != want {
+		t.Errorf("got %+v, want %+v", total, want)
+	}
+}
+
+func BenchmarkStats(b *testing.B) {
+	data := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dög\n"), 1<<14)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := Stats(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Command wc counts lines, words, runes and bytes of files like wc does,
+// plus invalid UTF-8 and the longest line. The files are read
+// concurrently, each in a single pass, so they can be larger than memory.
+//
+//	wc [-j n] [file...]
+//
+// Without files it reads standard input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+
+	"ch15/text"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type result struct {
+	counts text.Counts
+	err    error
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("wc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	parallel := fs.Int("j", runtime.GOMAXPROCS(0), "files read at the same time")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	files := fs.Args()
+
+	var results []result
+	if len(files) == 0 {
+		c, err := text.Stats(stdin)
+		results = []result{{c, err}}
+		files = []string{"-"}
+	} else {
+		results = statsFiles(files, max(*parallel, 1))
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "lines\twords\trunes\tbytes\tinvalid\tlongest\t")
+	var total text.Counts
+	status := 0
+	for i, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(stderr, "wc: %v\n", r.err)
+			status = 1
+			continue
+		}
+		total.Add(r.counts)
+		printCounts(tw, r.counts, files[i])
+	}
+	if len(files) > 1 {
+		printCounts(tw, total, "total")
+	}
+	tw.Flush()
+	return status
+}
+
+// statsFiles counts the files with up to parallel of them open at a time,
+// the results are in the order of files.
+func statsFiles(files []string, parallel int) []result {
+	results := make([]result, len(files))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, name := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c, err := text.StatsFile(name)
+			results[i] = result{c, err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func printCounts(w io.Writer, c text.Counts, name string) {
+	fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t %s\n", c.Lines, c.Words, c.Runes, c.Bytes, c.InvalidUTF8, c.LongestLine, name)
+}
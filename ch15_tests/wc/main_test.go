@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ch15/golden"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(a, []byte("one two\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("fünf\xff\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"-j", "1", a, filepath.Join(dir, "missing.txt"), b}, nil, &stdout, &stderr)
+	if status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	if !strings.Contains(stderr.String(), "missing.txt") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+
+	golden.Assert(t, "run", stdout.Bytes(), golden.Path(dir, "<DIR>"))
+}
+
+func TestRunStdin(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if status := run(nil, strings.NewReader("a b c\n"), &stdout, &stderr); status != 0 {
+		t.Fatalf("status = %d, stderr %q", status, stderr.String())
+	}
+	if got := strings.Fields(strings.Split(stdout.String(), "\n")[1]); strings.Join(got, " ") != "1 3 6 6 0 5 -" {
+		t.Errorf("got %q", got)
+	}
+}
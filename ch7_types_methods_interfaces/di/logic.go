@@ -4,6 +4,7 @@ import "errors"
 
 type Logic interface {
 	SayHello(userID string) (string, error)
+	SayGoodBye(userID string) (string, error)
 }
 
 type SimpleLogic struct {
@@ -21,7 +22,7 @@ func (sl SimpleLogic) SayHello(userID string) (string, error) {
 }
 
 func (sl SimpleLogic) SayGoodBye(userID string) (string, error) {
-	sl.l.Log("in SayHello for " + userID)
+	sl.l.Log("in SayGoodBye for " + userID)
 	name, ok := sl.ds.UserNameForID(userID)
 	if !ok {
 		return "", errors.New("unknown user")
@@ -1,10 +1,22 @@
 package main
 
-import "net/http"
+import (
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+)
+
+type UserDirectory interface {
+	Users() map[string]string
+}
 
 type Controller struct {
 	l     Logger
 	logic Logic
+	users UserDirectory
 }
 
 func (c Controller) SayHello(w http.ResponseWriter, r *http.Request) {
@@ -19,18 +31,58 @@ func (c Controller) SayHello(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(message))
 }
 
-func NewController(l Logger, logic Logic) Controller {
+func (c Controller) SayGoodBye(w http.ResponseWriter, r *http.Request) {
+	c.l.Log("In SayGoodBye")
+	userID := r.URL.Query().Get("user_id")
+	message, err := c.logic.SayGoodBye(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Write([]byte(message))
+}
+
+// ListUsers writes the users as a JSON object of names by id.
+func (c Controller) ListUsers(w http.ResponseWriter, r *http.Request) {
+	c.l.Log("In ListUsers")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.users.Users())
+}
+
+func NewController(l Logger, logic Logic, users UserDirectory) Controller {
 	return Controller{
 		l:     l,
 		logic: logic,
+		users: users,
 	}
 }
 
 func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dev := flag.Bool("dev", false, "serve the UI from -ui-dir instead of the binary, for live editing")
+	uiDir := flag.String("ui-dir", "ui", "directory the UI is served from in dev mode")
+	flag.Parse()
+
 	l := LoggerAdapter(LogOutput)
-	ds := NewSimpleDataStore()
+	ds := NewSimpleDataStore("Fred", "Mary", "Pat")
 	logic := NewSimpleLogic(l, ds)
-	c := NewController(l, logic)
+	c := NewController(l, logic, ds)
+
+	var ui fs.FS
+	if *dev {
+		ui = os.DirFS(*uiDir)
+		l.Log("Serving the UI from " + *uiDir)
+	} else {
+		var err error
+		if ui, err = fs.Sub(embeddedUI, "ui"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	http.HandleFunc("/hello", c.SayHello)
-	http.ListenAndServe(":8080", nil)
+	http.HandleFunc("/goodbye", c.SayGoodBye)
+	http.HandleFunc("/users", c.ListUsers)
+	http.Handle("/", NewUIHandler(ui, *dev))
+	log.Fatal(http.ListenAndServe(*addr, nil))
 }
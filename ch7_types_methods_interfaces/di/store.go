@@ -1,6 +1,9 @@
 package main
 
-import "strconv"
+import (
+	"maps"
+	"strconv"
+)
 
 type DataStore interface {
 	UserNameForID(userID string) (string, bool)
@@ -15,6 +18,11 @@ func (sds SimpleDataStore) UserNameForID(userID string) (string, bool) {
 	return name, ok
 }
 
+// Users returns the names by user id.
+func (sds SimpleDataStore) Users() map[string]string {
+	return maps.Clone(sds.userData)
+}
+
 func NewSimpleDataStore(names ...string) SimpleDataStore {
 	store := SimpleDataStore{userData: map[string]string{}}
 	for i, v := range names {
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+//go:embed ui
+var embeddedUI embed.FS
+
+// UIHandler serves a single-page UI from a file system. Paths without a
+// file extension that don't exist or are directories get index.html, so the
+// UI can use them for its own routes.
+type UIHandler struct {
+	fsys fs.FS
+	dev  bool
+
+	// etags caches the ETag of each file unless in dev mode, the embedded
+	// files never change
+	etags sync.Map
+}
+
+// NewUIHandler serves the UI in fsys. In dev mode the files are expected to
+// change while running, nothing is cached.
+func NewUIHandler(fsys fs.FS, dev bool) *UIHandler {
+	return &UIHandler{fsys: fsys, dev: dev}
+}
+
+func (h *UIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := path.Clean(r.URL.Path)[1:]
+	if name == "" {
+		name = "index.html"
+	}
+	content, err := readFile(h.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "" {
+		name = "index.html"
+		content, err = readFile(h.fsys, name)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "can't read "+name, http.StatusInternalServerError)
+		return
+	}
+
+	if h.dev {
+		w.Header().Set("Cache-Control", "no-store")
+	} else {
+		// browsers keep the file but ask whether it changed, the ETag
+		// makes that cheap
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("ETag", h.etag(name, content))
+	// ServeContent sets the Content-Type from the extension and answers
+	// If-None-Match with 304 Not Modified
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// readFile reads the file name in fsys. A directory is treated like a
+// missing file, there's nothing to serve for it.
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.ReadFile(fsys, name)
+}
+
+func (h *UIHandler) etag(name string, content []byte) string {
+	if !h.dev {
+		if tag, ok := h.etags.Load(name); ok {
+			return tag.(string)
+		}
+	}
+	sum := sha256.Sum256(content)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if !h.dev {
+		h.etags.Store(name, tag)
+	}
+	return tag
+}
@@ -0,0 +1,39 @@
+"use strict";
+
+const form = document.getElementById("greet");
+const select = document.getElementById("user");
+const message = document.getElementById("message");
+const users = document.getElementById("users");
+
+function show(text, isError) {
+  message.textContent = text;
+  message.classList.toggle("error", isError);
+}
+
+async function loadUsers() {
+  const resp = await fetch("/users");
+  if (!resp.ok) {
+    show("Couldn't load the users: " + resp.status, true);
+    return;
+  }
+  const byID = await resp.json();
+  const ids = Object.keys(byID).sort((a, b) => a - b);
+  select.replaceChildren();
+  users.replaceChildren();
+  for (const id of ids) {
+    select.append(new Option(byID[id], id));
+    const row = users.insertRow();
+    row.insertCell().textContent = id;
+    row.insertCell().textContent = byID[id];
+  }
+}
+
+form.addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const kind = event.submitter.value;
+  const params = new URLSearchParams({ user_id: select.value });
+  const resp = await fetch("/" + kind + "?" + params);
+  show(await resp.text(), !resp.ok);
+});
+
+loadUsers();
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUIHandler(t *testing.T) {
+	ui, err := fs.Sub(embeddedUI, "ui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewUIHandler(ui, false)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"/", http.StatusOK, "text/html", "<title>Greetings</title>"},
+		{"/index.html", http.StatusOK, "text/html", "<title>Greetings</title>"},
+		{"/style.css", http.StatusOK, "text/css", "#message"},
+		{"/app.js", http.StatusOK, "javascript", "loadUsers"},
+		{"/users/2", http.StatusOK, "text/html", "<title>Greetings</title>"},
+		{"/missing.png", http.StatusNotFound, "", ""},
+		{"/../ui.go", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %s", ct, tt.contentType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body doesn't contain %q", tt.contains)
+			}
+			if rec.Header().Get("ETag") == "" {
+				t.Error("no ETag")
+			}
+		})
+	}
+}
+
+func TestUIHandlerETag(t *testing.T) {
+	fsys := fstest.MapFS{"index.html": {Data: []byte("v1")}}
+	for _, dev := range []bool{false, true} {
+		h := NewUIHandler(fsys, dev)
+		fsys["index.html"].Data = []byte("v1")
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		etag := rec.Header().Get("ETag")
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-None-Match", etag)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("dev=%v: unchanged file: status = %d, want 304", dev, rec.Code)
+		}
+
+		// only dev mode notices changes while running
+		fsys["index.html"].Data = []byte("v2")
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		want := http.StatusNotModified
+		if dev {
+			want = http.StatusOK
+		}
+		if rec.Code != want {
+			t.Errorf("dev=%v: changed file: status = %d, want %d", dev, rec.Code, want)
+		}
+	}
+}
+
+func TestUIHandlerDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":       {Data: []byte("index")},
+		"assets/logo.png":  {Data: []byte("png")},
+		"v1.2/release.txt": {Data: []byte("notes")},
+	}
+	h := NewUIHandler(fsys, false)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/assets", http.StatusOK, "index"},
+		{"/assets/", http.StatusOK, "index"},
+		{"/assets/logo.png", http.StatusOK, "png"},
+		{"/v1.2", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.status)
+			continue
+		}
+		if tt.status == http.StatusOK && rec.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, rec.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestUIHandlerMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewUIHandler(fstest.MapFS{}, false).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,191 @@
+// Package config holds the settings of own1. They're read from environment
+// variables (main also loads them from a .env file) and can be overridden by
+// command line flags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config are the settings of own1.
+type Config struct {
+	URI        string
+	Database   string
+	Collection string
+
+	MaxPoolSize uint64
+	MinPoolSize uint64
+
+	// ConnectTimeout bounds establishing a connection, OpTimeout bounds
+	// single operations like a find.
+	ConnectTimeout time.Duration
+	OpTimeout      time.Duration
+
+	// PingRetries is how often the startup ping is retried, waiting
+	// PingBackoff before the first retry and twice as long before every
+	// further one. That gives Mongo in docker-compose time to start.
+	PingRetries int
+	PingBackoff time.Duration
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() Config {
+	return Config{
+		URI:            "mongodb://localhost:27017",
+		Database:       "sample_mflix",
+		Collection:     "movies",
+		MaxPoolSize:    20,
+		MinPoolSize:    0,
+		ConnectTimeout: 10 * time.Second,
+		OpTimeout:      5 * time.Second,
+		PingRetries:    5,
+		PingBackoff:    500 * time.Millisecond,
+	}
+}
+
+// Load builds the configuration from Default, the environment variables
+// read through getenv and the flags in args, in that order of precedence.
+// The flags are registered on fs, so fs.Args() holds what's left of args
+// afterwards.
+func Load(fs *flag.FlagSet, args []string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+	var errs []error
+	envString(getenv, "MONGODB_URI", &cfg.URI)
+	envString(getenv, "MONGODB_DATABASE", &cfg.Database)
+	envString(getenv, "MONGODB_COLLECTION", &cfg.Collection)
+	errs = append(errs,
+		envUint(getenv, "MONGODB_MAX_POOL_SIZE", &cfg.MaxPoolSize),
+		envUint(getenv, "MONGODB_MIN_POOL_SIZE", &cfg.MinPoolSize),
+		envDuration(getenv, "MONGODB_CONNECT_TIMEOUT", &cfg.ConnectTimeout),
+		envDuration(getenv, "MONGODB_OP_TIMEOUT", &cfg.OpTimeout),
+		envInt(getenv, "MONGODB_PING_RETRIES", &cfg.PingRetries),
+		envDuration(getenv, "MONGODB_PING_BACKOFF", &cfg.PingBackoff),
+	)
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	fs.StringVar(&cfg.URI, "uri", cfg.URI, "Mongo connection string (MONGODB_URI)")
+	fs.StringVar(&cfg.Database, "db", cfg.Database, "database name (MONGODB_DATABASE)")
+	fs.StringVar(&cfg.Collection, "collection", cfg.Collection, "movies collection name (MONGODB_COLLECTION)")
+	fs.Uint64Var(&cfg.MaxPoolSize, "max-pool", cfg.MaxPoolSize, "maximum connections in the pool (MONGODB_MAX_POOL_SIZE)")
+	fs.Uint64Var(&cfg.MinPoolSize, "min-pool", cfg.MinPoolSize, "minimum connections in the pool (MONGODB_MIN_POOL_SIZE)")
+	fs.DurationVar(&cfg.ConnectTimeout, "connect-timeout", cfg.ConnectTimeout, "timeout for connecting (MONGODB_CONNECT_TIMEOUT)")
+	fs.DurationVar(&cfg.OpTimeout, "op-timeout", cfg.OpTimeout, "timeout for single operations (MONGODB_OP_TIMEOUT)")
+	fs.IntVar(&cfg.PingRetries, "ping-retries", cfg.PingRetries, "how often to retry the startup ping (MONGODB_PING_RETRIES)")
+	fs.DurationVar(&cfg.PingBackoff, "ping-backoff", cfg.PingBackoff, "wait before the first ping retry (MONGODB_PING_BACKOFF)")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that the settings make sense.
+func (c Config) Validate() error {
+	var errs []error
+	if !strings.HasPrefix(c.URI, "mongodb://") && !strings.HasPrefix(c.URI, "mongodb+srv://") {
+		errs = append(errs, errors.New("uri has to start with mongodb:// or mongodb+srv://"))
+	}
+	if c.Database == "" {
+		errs = append(errs, errors.New("database must not be empty"))
+	}
+	if c.Collection == "" {
+		errs = append(errs, errors.New("collection must not be empty"))
+	}
+	if c.MaxPoolSize != 0 && c.MinPoolSize > c.MaxPoolSize {
+		errs = append(errs, fmt.Errorf("min pool size %d is larger than max pool size %d", c.MinPoolSize, c.MaxPoolSize))
+	}
+	if c.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("connect timeout must be positive"))
+	}
+	if c.OpTimeout <= 0 {
+		errs = append(errs, errors.New("op timeout must be positive"))
+	}
+	if c.PingRetries < 0 {
+		errs = append(errs, errors.New("ping retries must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// RedactedURI returns the URI with its password replaced, so it can be
+// logged.
+func (c Config) RedactedURI() string {
+	return RedactURI(c.URI)
+}
+
+// RedactURI replaces the password in a Mongo connection string with xxxxx.
+// It doesn't use net/url, which can't parse URIs with several hosts like
+// mongodb://user:pw@host1,host2/.
+func RedactURI(uri string) string {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return uri
+	}
+	// a password may contain unescaped "/", "?" and "@", so the user info
+	// ends at the last @, not at the start of the path
+	at := strings.LastIndex(rest, "@")
+	if at < 0 {
+		return uri
+	}
+	userInfo, hosts := rest[:at], rest[at:]
+	if strings.ContainsAny(userInfo, "/?") {
+		// either the password contains them or the @ is part of the path or
+		// query, there's no telling which, so hide everything before it
+		return scheme + "://xxxxx" + hosts
+	}
+	user, _, hasPassword := strings.Cut(userInfo, ":")
+	if !hasPassword {
+		return uri
+	}
+	return scheme + "://" + user + ":xxxxx" + hosts
+}
+
+func envString(getenv func(string) string, key string, dst *string) {
+	if v := getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+func envUint(getenv func(string) string, key string, dst *uint64) error {
+	v := getenv(key)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func envInt(getenv func(string) string, key string, dst *int) error {
+	v := getenv(key)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func envDuration(getenv func(string) string, key string, dst *time.Duration) error {
+	v := getenv(key)
+	if v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,62 @@
+// Package connect opens Mongo connections according to a config.Config and
+// makes sure the server is actually reachable before handing them out.
+package connect
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"own1_mongodb/config"
+)
+
+// Mongo connects to the server in cfg and pings it, retrying the ping as
+// configured. It returns an error if the server still isn't reachable after
+// the last retry.
+func Mongo(ctx context.Context, cfg config.Config) (*mongo.Client, error) {
+	opts := options.Client().
+		ApplyURI(cfg.URI).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetServerSelectionTimeout(cfg.ConnectTimeout)
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", cfg.RedactedURI(), err)
+	}
+	if err := Ping(ctx, client, cfg); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client, nil
+}
+
+// Ping pings the primary, retrying cfg.PingRetries times with exponential
+// back-off. Every attempt is bounded by cfg.ConnectTimeout.
+func Ping(ctx context.Context, client *mongo.Client, cfg config.Config) error {
+	backoff := cfg.PingBackoff
+	for attempt := 0; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+		err := client.Ping(pingCtx, readpref.Primary())
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == cfg.PingRetries {
+			return fmt.Errorf("pinging %s: %w", cfg.RedactedURI(), err)
+		}
+		log.Printf("Mongo isn't reachable yet, retrying in %v: %v", backoff, err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
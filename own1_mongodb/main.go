@@ -3,90 +3,418 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"own1_mongodb/config"
+	"own1_mongodb/connect"
+	"own1_mongodb/migrate"
+	"own1_mongodb/movies"
+	"own1_mongodb/transfer"
+	"own1_mongodb/watch"
 )
 
+const usage = `usage: own1_mongodb [flags] [command]
+
+commands:
+  find [title]              print a movie, "Back to the Future" by default
+  migrate [flags]           apply the pending migrations
+  watch [flags]             forward changes on the movies to sinks
+  import [flags] file       upsert movies from a jsonl, extjson or csv file
+  export [flags]            write all movies as jsonl, extjson or csv
+  health                    check that Mongo is reachable
+
+flags:
+`
+
+// app is what every command needs
+type app struct {
+	cfg config.Config
+	db  *mongo.Database
+}
+
+func (a app) movies() *mongo.Collection {
+	return a.db.Collection(a.cfg.Collection)
+}
+
+// opContext bounds a single operation by the configured op timeout
+func (a app) opContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, a.cfg.OpTimeout)
+}
+
 func main() {
 	// https://www.mongodb.com/docs/drivers/go/current/quick-start/#std-label-golang-quickstart
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
-	uri := os.Getenv("MONGODB_URI")
 
-	if uri == "" {
-		log.Fatal("Set your 'MONGDOB_URI' env variable")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	command := "find"
+	var args []string
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+		args = flag.Args()[1:]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if command == "health" {
+		err = health(ctx, cfg)
+	} else {
+		err = run(ctx, cfg, command, args)
+		if err != nil {
+			log.Println(err)
+		}
 	}
+	if err != nil {
+		stop()
+		os.Exit(1)
+	}
+}
 
-	log.Printf("Trying to connect to mongo using uri %s", uri)
+// run connects to Mongo and runs command, the connection is closed again
+// before it returns
+func run(ctx context.Context, cfg config.Config, command string, args []string) error {
+	log.Printf("Trying to connect to mongo using uri %s", cfg.RedactedURI())
 
-	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(uri))
+	client, err := connect.Mongo(ctx, cfg)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	log.Printf("Connection established")
 
 	defer func() {
-		if err := client.Disconnect(context.TODO()); err != nil {
-			panic(err)
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.OpTimeout)
+		defer cancel()
+		if err := client.Disconnect(ctx); err != nil {
+			log.Println(err)
 		}
 	}()
 
-	databaseName := "sample_mflix"
-	collectionName := "movies"
+	a := app{cfg: cfg, db: client.Database(cfg.Database)}
+
+	switch command {
+	case "migrate":
+		return a.migrate(ctx, args)
+	case "find":
+		a.warnPendingMigrations(ctx)
+		return a.findMovie(ctx, args)
+	case "watch":
+		return a.watchMovies(ctx, args)
+	case "import":
+		a.warnPendingMigrations(ctx)
+		return a.importMovies(ctx, args)
+	case "export":
+		return a.exportMovies(ctx, args)
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %s", command)
+	}
+}
+
+// healthReport is printed by the health command
+type healthReport struct {
+	OK        bool     `json:"ok"`
+	URI       string   `json:"uri"`
+	Error     string   `json:"error,omitempty"`
+	Latency   string   `json:"latency,omitempty"`
+	SetName   string   `json:"setName,omitempty"`
+	Primary   string   `json:"primary,omitempty"`
+	Writable  bool     `json:"isWritablePrimary"`
+	Hosts     []string `json:"hosts,omitempty"`
+	Databases []string `json:"databases,omitempty"`
+}
 
-	database := client.Database(databaseName)
+// health prints whether Mongo is reachable, and if it is, what the replica
+// set looks like. It returns an error if Mongo isn't healthy.
+func health(ctx context.Context, cfg config.Config) error {
+	report := healthReport{URI: cfg.RedactedURI()}
+	err := func() error {
+		start := time.Now()
+		client, err := connect.Mongo(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.OpTimeout)
+			defer cancel()
+			client.Disconnect(ctx)
+		}()
+		report.Latency = time.Since(start).String()
 
-	ensureCollectionExists(database, collectionName)
+		opCtx, cancel := context.WithTimeout(ctx, cfg.OpTimeout)
+		defer cancel()
+		var hello struct {
+			SetName           string   `bson:"setName"`
+			Primary           string   `bson:"primary"`
+			IsWritablePrimary bool     `bson:"isWritablePrimary"`
+			Hosts             []string `bson:"hosts"`
+		}
+		err = client.Database("admin").RunCommand(opCtx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+		if err != nil {
+			return err
+		}
+		report.SetName = hello.SetName
+		report.Primary = hello.Primary
+		report.Writable = hello.IsWritablePrimary
+		report.Hosts = hello.Hosts
+		report.Databases, err = client.ListDatabaseNames(opCtx, bson.D{})
+		return err
+	}()
+	report.OK = err == nil
+	if err != nil {
+		report.Error = err.Error()
+	}
 
-	coll := database.Collection("movies")
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(report); encErr != nil {
+		return encErr
+	}
+	return err
+}
 
+func (a app) findMovie(ctx context.Context, args []string) error {
 	title := "Back to the Future"
+	if len(args) > 0 {
+		title = args[0]
+	}
 
-	log.Printf("Trying to read from collection %s ", coll.Name())
-
-	var result bson.M
-	err = coll.FindOne(context.TODO(), bson.D{{"title", title}}).Decode(&result)
+	log.Printf("Trying to read movie %s", title)
 
-	log.Printf("Read from collection %s, lets see what happens", coll.Name())
+	ctx, cancel := a.opContext(ctx)
+	defer cancel()
 
-	if err == mongo.ErrNoDocuments {
+	var repo movies.Repository = movies.NewMongo(a.movies())
+	movie, err := repo.FindByTitle(ctx, title)
+	if errors.Is(err, movies.ErrNotFound) {
 		log.Printf("No document was found with the title %s\n", title)
-		return
+		return nil
 	}
 
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	jsonData, err := json.MarshalIndent(result, "", "  ")
+	jsonData, err := json.MarshalIndent(movie, "", "  ")
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	fmt.Printf("%s\n", jsonData)
+	return nil
 }
 
-func ensureCollectionExists(database *mongo.Database, collectionName string) {
-	_, err := database.ListCollectionNames(context.TODO(), bson.D{})
+// migrate applies the migrations that weren't applied yet, they create the
+// collections with their validators and indexes. Each migration gets its
+// own timeout, building an index on a large collection takes a while.
+func (a app) migrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", time.Minute, "how long a single migration may take")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	migrator, err := migrate.New(a.db, migrate.Embedded(), migrate.WithTimeout(*timeout))
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Apply(ctx)
+	for _, version := range applied {
+		log.Printf("Applied migration %04d", version)
+	}
+	return err
+}
 
+// warnPendingMigrations logs the migrations that weren't applied yet. Other
+// commands don't apply them, changing indexes and validators is up to the
+// migrate command.
+func (a app) warnPendingMigrations(ctx context.Context) {
+	migrator, err := migrate.New(a.db, migrate.Embedded())
 	if err != nil {
-		panic(err)
+		log.Println(err)
+		return
 	}
 
-	//if err != nil {
-	//	log.Printf("Collection %s does not exist, creating it")
-	//	err := database.CreateCollection(context.TODO(), collectionName)
-	//	if err != nil {
-	//		log.Fatal("Couldn't create collection", collectionName)
-	//	}
-	//	log.Printf("Created collection %s", collectionName)
-	//}
+	ctx, cancel := a.opContext(ctx)
+	defer cancel()
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		log.Printf("Can't check for pending migrations: %v", err)
+		return
+	}
+	for _, m := range pending {
+		log.Printf("Migration %04d_%s is pending, run the migrate command to apply it", m.Version, m.Name)
+	}
+}
+
+// sinkFlags collects the repeatable -sink flag
+type sinkFlags []string
+
+func (sf *sinkFlags) String() string {
+	return strings.Join(*sf, ",")
+}
+
+func (sf *sinkFlags) Set(value string) error {
+	*sf = append(*sf, value)
+	return nil
+}
+
+// watchMovies forwards changes on coll to the sinks given by -sink until
+// interrupted. A sink is "stdout", "file:<path>" or "webhook:<url>".
+func (a app) watchMovies(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	tokenFile := fs.String("token", ".watch_token", "file the resume token is kept in")
+	var sinkArgs sinkFlags
+	fs.Var(&sinkArgs, "sink", "where to send changes: stdout, file:<path> or webhook:<url>, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(sinkArgs) == 0 {
+		sinkArgs = sinkFlags{"stdout"}
+	}
+
+	var sink watch.MultiSink
+	for _, arg := range sinkArgs {
+		kind, target, _ := strings.Cut(arg, ":")
+		switch kind {
+		case "stdout":
+			sink = append(sink, watch.NewJSONSink(os.Stdout))
+		case "file":
+			fileSink, err := watch.NewFileSink(target)
+			if err != nil {
+				return err
+			}
+			sink = append(sink, fileSink)
+		case "webhook":
+			sink = append(sink, watch.WebhookSink{URL: target, Client: &http.Client{Timeout: 10 * time.Second}})
+		default:
+			sink.Close()
+			return fmt.Errorf("unknown sink %s", arg)
+		}
+	}
+	defer sink.Close()
+
+	log.Printf("Watching %s for changes", a.cfg.Collection)
+	return watch.Watch(ctx, a.movies(), watch.FileTokenStore{Path: *tokenFile}, sink)
+}
+
+// importMovies upserts the movies of a JSON Lines, extended JSON or CSV file
+// by title. If an import fails, running it again resumes after the last
+// batch that was written.
+func (a app) importMovies(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "jsonl, extjson or csv, guessed from the file extension if empty")
+	batchSize := fs.Int("batch", transfer.DefaultBatchSize, "documents per bulk write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: import [-format jsonl|extjson|csv] [-batch n] file")
+	}
+	path := fs.Arg(0)
+
+	ff, err := fileFormat(*format, path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := transfer.NewReader(ff, f)
+	if err != nil {
+		return err
+	}
+	im := transfer.Importer{
+		Coll:       a.movies(),
+		BatchSize:  *batchSize,
+		Checkpoint: path + ".checkpoint",
+		Progress: func(p transfer.Progress) {
+			log.Printf("Read %d, inserted %d, updated %d", p.Read, p.Inserted, p.Updated)
+		},
+	}
+	p, err := im.Import(ctx, r)
+	if err != nil {
+		return fmt.Errorf("import failed after %d documents, run it again to resume: %w", p.Read, err)
+	}
+	log.Printf("Imported %d documents", p.Read)
+	return nil
+}
+
+// exportMovies writes all movies, ordered by title, to a file or stdout.
+func (a app) exportMovies(ctx context.Context, args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "jsonl, extjson or csv, guessed from -o if empty")
+	out := fs.String("o", "", "file to write to, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ff, err := fileFormat(*format, *out)
+	if err != nil {
+		return err
+	}
+	var dest io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		// Close can report a write that failed, e.g. on a full disk
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		dest = f
+	}
+
+	w, err := transfer.NewWriter(ff, dest)
+	if err != nil {
+		return err
+	}
+	cursor, err := a.movies().Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "title", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	n, err := transfer.Export(ctx, cursor, w)
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported %d documents", n)
+	return nil
+}
+
+func fileFormat(format, path string) (transfer.Format, error) {
+	if format == "" {
+		return transfer.FormatFromPath(path), nil
+	}
+	return transfer.ParseFormat(format)
 }
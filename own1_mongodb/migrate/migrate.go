@@ -0,0 +1,336 @@
+// Package migrate applies versioned schema migrations to a Mongo database.
+// A migration is a JSON file named <version>_<name>.json, e.g.
+// 0001_create_movies.json, that lists collections to create (optionally with
+// a JSON schema validator) and indexes to build. Migrations are applied in
+// version order and every applied version is recorded in the "migrations"
+// collection, so running Apply again only applies the new ones.
+//
+// The files are parsed as Mongo extended JSON, which keeps the order of
+// keys. That matters for compound indexes.
+package migrate
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//go:embed migrations/*.json
+var files embed.FS
+
+// Embedded returns the migrations shipped with own1.
+func Embedded() fs.FS {
+	sub, err := fs.Sub(files, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// HistoryCollection records the applied migrations.
+const HistoryCollection = "migrations"
+
+// Migration is a single migration file.
+type Migration struct {
+	Version           int              `bson:"-"`
+	Name              string           `bson:"-"`
+	Description       string           `bson:"description"`
+	CreateCollections []CollectionSpec `bson:"createCollections"`
+	Indexes           []IndexSpec      `bson:"indexes"`
+}
+
+// CollectionSpec describes a collection to create. If the collection already
+// exists, its validator is updated instead.
+type CollectionSpec struct {
+	Name             string `bson:"name"`
+	Validator        bson.D `bson:"validator,omitempty"`
+	ValidationLevel  string `bson:"validationLevel,omitempty"`
+	ValidationAction string `bson:"validationAction,omitempty"`
+}
+
+// IndexSpec describes an index to build. A collection can only have one
+// text index, so a text index is skipped if the collection already has one.
+type IndexSpec struct {
+	Collection string `bson:"collection"`
+	Name       string `bson:"name"`
+	Keys       bson.D `bson:"keys"`
+	Unique     bool   `bson:"unique,omitempty"`
+}
+
+// Load reads all *.json files in the root of fsys and returns them ordered by
+// version. Versions have to be unique.
+func Load(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	for _, p := range paths {
+		m, err := loadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s",
+				migrations[i].Version, migrations[i-1].Name, migrations[i].Name)
+		}
+	}
+	return migrations, nil
+}
+
+func loadFile(fsys fs.FS, p string) (Migration, error) {
+	base := strings.TrimSuffix(path.Base(p), ".json")
+	versionStr, name, ok := strings.Cut(base, "_")
+	version, err := strconv.Atoi(versionStr)
+	if !ok || err != nil {
+		return Migration{}, fmt.Errorf("migration %s: file name must look like 0001_name.json", p)
+	}
+	data, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return Migration{}, err
+	}
+	var m Migration
+	if err := bson.UnmarshalExtJSON(data, false, &m); err != nil {
+		return Migration{}, fmt.Errorf("migration %s: %w", p, err)
+	}
+	m.Version = version
+	m.Name = name
+	return m, nil
+}
+
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	timeout    time.Duration
+}
+
+// Option configures a Migrator.
+type Option func(*Migrator)
+
+// WithTimeout bounds every single migration by d, instead of all of them
+// sharing the deadline of the context passed to Apply.
+func WithTimeout(d time.Duration) Option {
+	return func(m *Migrator) {
+		m.timeout = d
+	}
+}
+
+// New returns a Migrator for the migrations in fsys, usually Embedded().
+func New(db *mongo.Database, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m := &Migrator{db: db, migrations: migrations}
+	for _, o := range opts {
+		o(m)
+	}
+	return m, nil
+}
+
+// Applied returns the versions that were applied already.
+func (m *Migrator) Applied(ctx context.Context) ([]int, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := m.db.Collection(HistoryCollection).Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(records))
+	for _, r := range records {
+		versions = append(versions, r.Version)
+	}
+	return versions, nil
+}
+
+// Pending returns the migrations that weren't applied yet, in version order.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if !slices.Contains(applied, migration.Version) {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Apply applies every migration that wasn't applied yet, in version order,
+// and returns the versions it applied. It stops at the first migration that
+// fails, that one isn't recorded and is tried again by the next Apply.
+func (m *Migrator) Apply(ctx context.Context) ([]int, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, migration := range pending {
+		if err := m.apply(ctx, migration); err != nil {
+			return versions, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		versions = append(versions, migration.Version)
+	}
+	return versions, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	for _, c := range migration.CreateCollections {
+		if err := m.createCollection(ctx, c); err != nil {
+			return err
+		}
+	}
+	for _, i := range migration.Indexes {
+		if err := m.createIndex(ctx, i); err != nil {
+			return err
+		}
+	}
+	_, err := m.db.Collection(HistoryCollection).InsertOne(ctx, record{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		AppliedAt: time.Now().UTC(),
+	})
+	return err
+}
+
+func (m *Migrator) createCollection(ctx context.Context, c CollectionSpec) error {
+	names, err := m.db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: c.Name}})
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		opts := options.CreateCollection()
+		if c.Validator != nil {
+			opts.SetValidator(c.Validator)
+		}
+		if c.ValidationLevel != "" {
+			opts.SetValidationLevel(c.ValidationLevel)
+		}
+		if c.ValidationAction != "" {
+			opts.SetValidationAction(c.ValidationAction)
+		}
+		if err := m.db.CreateCollection(ctx, c.Name, opts); err != nil {
+			return fmt.Errorf("creating collection %s: %w", c.Name, err)
+		}
+		return nil
+	}
+
+	if c.Validator == nil {
+		return nil
+	}
+	cmd := bson.D{
+		{Key: "collMod", Value: c.Name},
+		{Key: "validator", Value: c.Validator},
+	}
+	if c.ValidationLevel != "" {
+		cmd = append(cmd, bson.E{Key: "validationLevel", Value: c.ValidationLevel})
+	}
+	if c.ValidationAction != "" {
+		cmd = append(cmd, bson.E{Key: "validationAction", Value: c.ValidationAction})
+	}
+	if err := m.db.RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("updating validator of %s: %w", c.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) createIndex(ctx context.Context, i IndexSpec) error {
+	if len(i.Keys) == 0 {
+		return errors.New("index " + i.Name + " has no keys")
+	}
+	indexes := m.db.Collection(i.Collection).Indexes()
+	if isText(i.Keys) {
+		// a collection can only have one text index, and creating one with
+		// a new name fails if another one exists, like in the sample data
+		existing, err := textIndex(ctx, indexes)
+		if err != nil {
+			return fmt.Errorf("listing indexes of %s: %w", i.Collection, err)
+		}
+		if existing != "" {
+			return nil
+		}
+	}
+	model := mongo.IndexModel{
+		Keys:    i.Keys,
+		Options: options.Index().SetName(i.Name),
+	}
+	if i.Unique {
+		model.Options.SetUnique(true)
+	}
+	if _, err := indexes.CreateOne(ctx, model); err != nil {
+		return fmt.Errorf("creating index %s on %s: %w", i.Name, i.Collection, err)
+	}
+	return nil
+}
+
+func isText(keys bson.D) bool {
+	for _, k := range keys {
+		if k.Value == "text" {
+			return true
+		}
+	}
+	return false
+}
+
+// textIndex returns the name of the text index of a collection, "" if it
+// has none.
+func textIndex(ctx context.Context, indexes mongo.IndexView) (string, error) {
+	cursor, err := indexes.List(ctx)
+	if err != nil {
+		return "", err
+	}
+	var specs []bson.Raw
+	if err := cursor.All(ctx, &specs); err != nil {
+		return "", err
+	}
+	return textIndexName(specs), nil
+}
+
+// textIndexName finds the text index in the output of listIndexes. Mongo
+// reports the keys of a text index as {_fts: "text", _ftsx: 1}, whatever
+// fields it covers.
+func textIndexName(specs []bson.Raw) string {
+	for _, spec := range specs {
+		fts, ok := spec.Lookup("key", "_fts").StringValueOK()
+		if ok && fts == "text" {
+			name, _ := spec.Lookup("name").StringValueOK()
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	m, err := New(nil, Embedded())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(m.migrations) < 2 {
+		t.Fatal("expected at least 2 migrations, got", len(m.migrations))
+	}
+	for i, migration := range m.migrations {
+		if migration.Version != i+1 {
+			t.Error("expected version", i+1, "got", migration.Version)
+		}
+	}
+}
+
+func TestLoadKeepsKeyOrder(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_index.json": {Data: []byte(`{"indexes": [
+			{"collection": "movies", "name": "compound", "keys": {"year": 1, "genres": -1, "title": 1}}
+		]}`)},
+		"0001_create.json": {Data: []byte(`{"createCollections": [{"name": "movies"}]}`)},
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if migrations[0].Name != "create" || migrations[1].Name != "index" {
+		t.Error("expected migrations in version order, got", migrations[0].Name, migrations[1].Name)
+	}
+	keys := migrations[1].Indexes[0].Keys
+	expected := bson.D{{Key: "year", Value: int32(1)}, {Key: "genres", Value: int32(-1)}, {Key: "title", Value: int32(1)}}
+	if len(keys) != len(expected) {
+		t.Fatal("expected", expected, "got", keys)
+	}
+	for i := range keys {
+		if keys[i] != expected[i] {
+			t.Error("expected", expected, "got", keys)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	data := map[string]fstest.MapFS{
+		"bad name": {"create.json": {Data: []byte(`{}`)}},
+		"bad json": {"0001_create.json": {Data: []byte(`{`)}},
+		"duplicate version": {
+			"0001_a.json": {Data: []byte(`{}`)},
+			"0001_b.json": {Data: []byte(`{}`)},
+		},
+	}
+	for name, fsys := range data {
+		if _, err := Load(fsys); err == nil {
+			t.Error(name + ": expected an error")
+		}
+	}
+}
+
+func TestTextIndexName(t *testing.T) {
+	spec := func(doc bson.D) bson.Raw {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	id := spec(bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}})
+	text := spec(bson.D{
+		{Key: "v", Value: 2},
+		{Key: "key", Value: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}},
+		{Key: "name", Value: "cast_text_fullplot_text_genres_text_title_text"},
+		{Key: "weights", Value: bson.D{{Key: "title", Value: 1}}},
+	})
+
+	if name := textIndexName([]bson.Raw{id}); name != "" {
+		t.Error("expected no text index, got", name)
+	}
+	if name := textIndexName([]bson.Raw{id, text}); name != "cast_text_fullplot_text_genres_text_title_text" {
+		t.Error("expected the text index of the sample data, got", name)
+	}
+}
+
+func TestIsText(t *testing.T) {
+	if !isText(bson.D{{Key: "title", Value: "text"}}) {
+		t.Error("expected a text index")
+	}
+	if isText(bson.D{{Key: "year", Value: int32(1)}, {Key: "genres", Value: int32(1)}}) {
+		t.Error("expected no text index")
+	}
+}
@@ -0,0 +1,107 @@
+package movies
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ Repository = (*Memory)(nil)
+
+// Memory is a Repository that keeps its movies in memory. It's meant for
+// tests and is safe for concurrent use.
+type Memory struct {
+	mu     sync.RWMutex
+	movies map[primitive.ObjectID]Movie
+}
+
+// NewMemory returns a Memory repository holding movies.
+func NewMemory(movies ...Movie) *Memory {
+	m := &Memory{movies: map[primitive.ObjectID]Movie{}}
+	for _, movie := range movies {
+		m.Insert(context.Background(), movie)
+	}
+	return m
+}
+
+func (m *Memory) FindByTitle(_ context.Context, title string) (Movie, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, movie := range m.movies {
+		if movie.Title == title {
+			return clone(movie), nil
+		}
+	}
+	return Movie{}, ErrNotFound
+}
+
+func (m *Memory) Search(_ context.Context, q Query) ([]Movie, error) {
+	m.mu.RLock()
+	var matches []Movie
+	for _, movie := range m.movies {
+		if matchesQuery(q, movie) {
+			matches = append(matches, clone(movie))
+		}
+	}
+	m.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b Movie) int {
+		return cmp.Or(cmp.Compare(a.Year, b.Year), cmp.Compare(a.Title, b.Title))
+	})
+	start := min(q.skip(), len(matches))
+	end := min(start+q.limit(), len(matches))
+	return append([]Movie{}, matches[start:end]...), nil
+}
+
+func (m *Memory) Insert(_ context.Context, movie Movie) (Movie, error) {
+	if movie.ID.IsZero() {
+		movie.ID = primitive.NewObjectID()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.movies[movie.ID] = clone(movie)
+	return movie, nil
+}
+
+func (m *Memory) Update(_ context.Context, movie Movie) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.movies[movie.ID]; !ok {
+		return ErrNotFound
+	}
+	m.movies[movie.ID] = clone(movie)
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.movies[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.movies, id)
+	return nil
+}
+
+func matchesQuery(q Query, movie Movie) bool {
+	if q.Genre != "" && !slices.Contains(movie.Genres, q.Genre) {
+		return false
+	}
+	if q.FromYear != 0 && movie.Year < q.FromYear {
+		return false
+	}
+	if q.ToYear != 0 && movie.Year > q.ToYear {
+		return false
+	}
+	return true
+}
+
+// clone copies the slices of movie, so callers can't change what's stored.
+func clone(movie Movie) Movie {
+	movie.Genres = slices.Clone(movie.Genres)
+	movie.Cast = slices.Clone(movie.Cast)
+	return movie
+}
@@ -0,0 +1,104 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func sampleMovies() *Memory {
+	return NewMemory(
+		Movie{Title: "Back to the Future", Year: 1985, Genres: []string{"Adventure", "Comedy", "Sci-Fi"}},
+		Movie{Title: "Back to the Future Part II", Year: 1989, Genres: []string{"Adventure", "Comedy", "Sci-Fi"}},
+		Movie{Title: "Alien", Year: 1979, Genres: []string{"Horror", "Sci-Fi"}},
+		Movie{Title: "Aliens", Year: 1986, Genres: []string{"Action", "Sci-Fi"}},
+		Movie{Title: "Ghostbusters", Year: 1984, Genres: []string{"Comedy", "Fantasy"}},
+	)
+}
+
+func titles(movies []Movie) []string {
+	var result []string
+	for _, m := range movies {
+		result = append(result, m.Title)
+	}
+	return result
+}
+
+func TestFindByTitle(t *testing.T) {
+	repo := sampleMovies()
+	movie, err := repo.FindByTitle(context.Background(), "Alien")
+	if err != nil || movie.Year != 1979 {
+		t.Error("expected Alien from 1979, got", movie, err)
+	}
+	if _, err := repo.FindByTitle(context.Background(), "Jaws"); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound, got", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	repo := sampleMovies()
+	data := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"genre", Query{Genre: "Comedy"},
+			[]string{"Ghostbusters", "Back to the Future", "Back to the Future Part II"}},
+		{"year range", Query{FromYear: 1984, ToYear: 1986},
+			[]string{"Ghostbusters", "Back to the Future", "Aliens"}},
+		{"genre and year", Query{Genre: "Sci-Fi", ToYear: 1985},
+			[]string{"Alien", "Back to the Future"}},
+		{"first page", Query{PageSize: 2},
+			[]string{"Alien", "Ghostbusters"}},
+		{"last page", Query{Page: 2, PageSize: 2},
+			[]string{"Back to the Future Part II"}},
+		{"past the end", Query{Page: 5, PageSize: 2},
+			nil},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			movies, err := repo.Search(context.Background(), d.query)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			got := titles(movies)
+			if len(got) != len(d.expected) {
+				t.Fatal("expected", d.expected, "got", got)
+			}
+			for i := range got {
+				if got[i] != d.expected[i] {
+					t.Error("expected", d.expected, "got", got)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemory()
+
+	movie, err := repo.Insert(ctx, Movie{Title: "Jaws", Year: 1975})
+	if err != nil || movie.ID.IsZero() {
+		t.Fatal("expected an ID, got", movie.ID, err)
+	}
+
+	movie.Ratings = Ratings{Rating: 8.1, Votes: 1000}
+	if err := repo.Update(ctx, movie); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	found, _ := repo.FindByTitle(ctx, "Jaws")
+	if found.Ratings.Rating != 8.1 {
+		t.Error("expected updated rating, got", found.Ratings)
+	}
+
+	if err := repo.Delete(ctx, movie.ID); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := repo.Delete(ctx, movie.ID); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound, got", err)
+	}
+	if err := repo.Update(ctx, movie); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound, got", err)
+	}
+}
@@ -0,0 +1,123 @@
+package movies
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ Repository = (*Mongo)(nil)
+
+// Mongo is a Repository backed by a Mongo collection.
+type Mongo struct {
+	coll *mongo.Collection
+}
+
+// NewMongo returns a Repository that uses coll.
+func NewMongo(coll *mongo.Collection) *Mongo {
+	return &Mongo{coll: coll}
+}
+
+func (m *Mongo) FindByTitle(ctx context.Context, title string) (Movie, error) {
+	var movie Movie
+	err := m.coll.FindOne(ctx, bson.D{{Key: "title", Value: title}}).Decode(&movie)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Movie{}, ErrNotFound
+	}
+	return movie, err
+}
+
+func (m *Mongo) Search(ctx context.Context, q Query) ([]Movie, error) {
+	filter := bson.D{}
+	if q.Genre != "" {
+		filter = append(filter, bson.E{Key: "genres", Value: q.Genre})
+	}
+	year := bson.D{}
+	if q.FromYear != 0 {
+		year = append(year, bson.E{Key: "$gte", Value: q.FromYear})
+	}
+	if q.ToYear != 0 {
+		year = append(year, bson.E{Key: "$lte", Value: q.ToYear})
+	}
+	if len(year) > 0 {
+		filter = append(filter, bson.E{Key: "year", Value: year})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "year", Value: 1}, {Key: "title", Value: 1}}).
+		SetSkip(int64(q.skip())).
+		SetLimit(int64(q.limit()))
+	cursor, err := m.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	movies := []Movie{}
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+func (m *Mongo) Insert(ctx context.Context, movie Movie) (Movie, error) {
+	if movie.ID.IsZero() {
+		movie.ID = primitive.NewObjectID()
+	}
+	if _, err := m.coll.InsertOne(ctx, movie); err != nil {
+		return Movie{}, err
+	}
+	return movie, nil
+}
+
+// Update only sets the fields Movie maps, the rest of the document is kept.
+func (m *Mongo) Update(ctx context.Context, movie Movie) error {
+	res, err := m.coll.UpdateOne(ctx, bson.D{{Key: "_id", Value: movie.ID}}, updateOf(movie))
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// updateOf returns the update document that writes the mapped fields of
+// movie. Empty genres and cast are removed, like omitempty does on insert.
+func updateOf(movie Movie) bson.D {
+	set := bson.D{
+		{Key: "title", Value: movie.Title},
+		{Key: "year", Value: movie.Year},
+		{Key: "imdb.rating", Value: movie.Ratings.Rating},
+		{Key: "imdb.votes", Value: movie.Ratings.Votes},
+	}
+	unset := bson.D{}
+	for _, f := range []struct {
+		key    string
+		values []string
+	}{{"genres", movie.Genres}, {"cast", movie.Cast}} {
+		if len(f.values) > 0 {
+			set = append(set, bson.E{Key: f.key, Value: f.values})
+		} else {
+			unset = append(unset, bson.E{Key: f.key, Value: ""})
+		}
+	}
+	update := bson.D{{Key: "$set", Value: set}}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+	return update
+}
+
+func (m *Mongo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := m.coll.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testCollection returns an empty collection on the server at MONGODB_URI
+// and drops it when the test is done. Without MONGODB_URI the test is
+// skipped.
+func testCollection(t *testing.T) *mongo.Collection {
+	t.Helper()
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("MONGODB_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := client.Database("movies_test").Collection(primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = coll.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+	return coll
+}
+
+func TestMongoUpdateKeepsUnmappedFields(t *testing.T) {
+	coll := testCollection(t)
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+	_, err := coll.InsertOne(ctx, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "title", Value: "Alien"},
+		{Key: "year", Value: 1979},
+		{Key: "cast", Value: bson.A{"Sigourney Weaver"}},
+		{Key: "plot", Value: "In space no one can hear you scream."},
+		{Key: "imdb", Value: bson.D{{Key: "rating", Value: 8.4}, {Key: "votes", Value: 500}, {Key: "id", Value: 78748}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewMongo(coll)
+	movie := Movie{ID: id, Title: "Alien", Year: 1979, Genres: []string{"Horror"}, Ratings: Ratings{8.5, 600}}
+	if err := repo.Update(ctx, movie); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var doc bson.M
+	if err := coll.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["plot"] != "In space no one can hear you scream." {
+		t.Error("expected plot to survive the update, got", doc["plot"])
+	}
+	if imdb, _ := doc["imdb"].(bson.M); imdb["id"] != int32(78748) {
+		t.Error("expected imdb.id to survive the update, got", doc["imdb"])
+	}
+	if _, ok := doc["cast"]; ok {
+		t.Error("expected the empty cast to be removed, got", doc["cast"])
+	}
+	got, err := repo.FindByTitle(ctx, "Alien")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Ratings != movie.Ratings || len(got.Genres) != 1 {
+		t.Errorf("expected %+v, got %+v", movie, got)
+	}
+
+	if err := repo.Update(ctx, Movie{ID: primitive.NewObjectID()}); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound, got", err)
+	}
+}
+
+func TestUpdateOf(t *testing.T) {
+	update := updateOf(Movie{Title: "Alien", Year: 1979, Genres: []string{"Horror"}, Ratings: Ratings{8.5, 600}})
+	expected := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "title", Value: "Alien"},
+			{Key: "year", Value: 1979},
+			{Key: "imdb.rating", Value: 8.5},
+			{Key: "imdb.votes", Value: 600},
+			{Key: "genres", Value: []string{"Horror"}},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "cast", Value: ""}}},
+	}
+	if !reflect.DeepEqual(update, expected) {
+		t.Errorf("expected %v, got %v", expected, update)
+	}
+}
@@ -0,0 +1,155 @@
+// Package movies reads and writes the movies of the sample_mflix database.
+// Business logic should depend on the Repository interface only: Mongo
+// implements it on top of a real collection, Memory keeps everything in a
+// map so the logic can be tested without a database.
+package movies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound is returned when no movie matches.
+var ErrNotFound = errors.New("movie not found")
+
+// Movie is a document of sample_mflix.movies. Only the fields we use are
+// mapped, everything else in the document is ignored.
+type Movie struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Title   string             `bson:"title" json:"title"`
+	Year    int                `bson:"year" json:"year"`
+	Genres  []string           `bson:"genres,omitempty" json:"genres,omitempty"`
+	Cast    []string           `bson:"cast,omitempty" json:"cast,omitempty"`
+	Ratings Ratings            `bson:"imdb,omitempty" json:"ratings"`
+}
+
+// UnmarshalBSON decodes a movie document. Like the ratings, the year isn't
+// always a number in the sample data, some movies have strings like "1995è".
+// Those are decoded by their leading digits, 0 if there are none.
+func (m *Movie) UnmarshalBSON(data []byte) error {
+	// plain has the fields of Movie but not its methods, so decoding it
+	// doesn't end up here again
+	type plain Movie
+	var doc struct {
+		Movie plain         `bson:",inline"`
+		Year  bson.RawValue `bson:"year"`
+	}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	*m = Movie(doc.Movie)
+	m.Year = year(doc.Year)
+	return nil
+}
+
+// year returns v as a year, 0 if it's missing or doesn't start with one.
+func year(v bson.RawValue) int {
+	if v.Type != bsontype.String {
+		return int(number(v))
+	}
+	s := strings.TrimSpace(v.StringValue())
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end == -1 {
+		end = len(s)
+	}
+	y, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return 0
+	}
+	return y
+}
+
+// Ratings are the IMDb ratings of a movie.
+type Ratings struct {
+	Rating float64 `bson:"rating,omitempty" json:"rating"`
+	Votes  int     `bson:"votes,omitempty" json:"votes"`
+}
+
+// UnmarshalBSONValue decodes the imdb subdocument. The sample data doesn't
+// always store rating and votes as numbers, some movies have strings like
+// "" or "1,234" instead. Those are parsed, and what isn't a number counts
+// as 0.
+func (r *Ratings) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*r = Ratings{}
+		return nil
+	case bsontype.EmbeddedDocument:
+	default:
+		return fmt.Errorf("cannot decode %s into Ratings", t)
+	}
+	doc := bson.Raw(data)
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	*r = Ratings{
+		Rating: number(doc.Lookup("rating")),
+		Votes:  int(number(doc.Lookup("votes"))),
+	}
+	return nil
+}
+
+// number returns v as a float64, 0 if it's missing or not a number.
+func number(v bson.RawValue) float64 {
+	switch v.Type {
+	case bsontype.Double:
+		return v.Double()
+	case bsontype.Int32:
+		return float64(v.Int32())
+	case bsontype.Int64:
+		return float64(v.Int64())
+	case bsontype.String:
+		s := strings.ReplaceAll(strings.TrimSpace(v.StringValue()), ",", "")
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	}
+	return 0
+}
+
+// Query selects movies for Search. Zero values mean "don't filter".
+type Query struct {
+	Genre    string
+	FromYear int
+	ToYear   int
+	// Page starts at 0. A PageSize of 0 uses DefaultPageSize.
+	Page     int
+	PageSize int
+}
+
+// DefaultPageSize is used when a Query doesn't set a PageSize.
+const DefaultPageSize = 20
+
+func (q Query) limit() int {
+	if q.PageSize <= 0 {
+		return DefaultPageSize
+	}
+	return q.PageSize
+}
+
+func (q Query) skip() int {
+	return max(q.Page, 0) * q.limit()
+}
+
+// Repository stores movies. Search returns the matching movies ordered by
+// year and title.
+type Repository interface {
+	FindByTitle(ctx context.Context, title string) (Movie, error)
+	Search(ctx context.Context, q Query) ([]Movie, error)
+	// Insert stores m and returns it with its new ID.
+	Insert(ctx context.Context, m Movie) (Movie, error)
+	// Update overwrites the fields of the movie with m.ID, ErrNotFound if
+	// there is none.
+	Update(ctx context.Context, m Movie) error
+	// Delete removes the movie with id, ErrNotFound if there is none.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,80 @@
+package movies
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDecodeRatings(t *testing.T) {
+	data := []struct {
+		name     string
+		imdb     any
+		expected Ratings
+	}{
+		{"numbers", bson.D{{Key: "rating", Value: 8.5}, {Key: "votes", Value: int32(1234)}}, Ratings{8.5, 1234}},
+		{"int64 votes", bson.D{{Key: "rating", Value: int32(7)}, {Key: "votes", Value: int64(99)}}, Ratings{7, 99}},
+		{"strings", bson.D{{Key: "rating", Value: ""}, {Key: "votes", Value: "1,234"}}, Ratings{0, 1234}},
+		{"unparsable", bson.D{{Key: "rating", Value: "n/a"}, {Key: "votes", Value: ""}}, Ratings{}},
+		{"missing fields", bson.D{{Key: "id", Value: int32(1)}}, Ratings{}},
+		{"null", nil, Ratings{}},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			doc, err := bson.Marshal(bson.D{{Key: "title", Value: "Alien"}, {Key: "imdb", Value: d.imdb}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m Movie
+			if err := bson.Unmarshal(doc, &m); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if m.Title != "Alien" || m.Ratings != d.expected {
+				t.Errorf("expected %+v, got %+v", d.expected, m.Ratings)
+			}
+		})
+	}
+}
+
+func TestDecodeYear(t *testing.T) {
+	data := []struct {
+		name     string
+		year     any
+		expected int
+	}{
+		{"int32", int32(1979), 1979},
+		{"int64", int64(1986), 1986},
+		{"double", 1984.0, 1984},
+		{"string", "1985", 1985},
+		{"trailing garbage", "1995è", 1995},
+		{"no digits", "unknown", 0},
+		{"empty", "", 0},
+		{"null", nil, 0},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			doc, err := bson.Marshal(bson.D{{Key: "title", Value: "Alien"}, {Key: "year", Value: d.year}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m Movie
+			if err := bson.Unmarshal(doc, &m); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if m.Title != "Alien" || m.Year != d.expected {
+				t.Errorf("expected %d, got %d", d.expected, m.Year)
+			}
+		})
+	}
+}
+
+func TestDecodeRatingsWrongType(t *testing.T) {
+	doc, err := bson.Marshal(bson.D{{Key: "imdb", Value: "8.5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Movie
+	if err := bson.Unmarshal(doc, &m); err == nil {
+		t.Error("expected an error for a string imdb field")
+	}
+}
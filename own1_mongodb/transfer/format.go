@@ -0,0 +1,283 @@
+// Package transfer imports movies into and exports them out of Mongo. It
+// understands three formats: JSON Lines (one plain JSON document per line),
+// Mongo extended JSON (one document per line, as written by mongoexport) and
+// CSV with the columns title, year, genres, cast, rating and votes, where
+// genres and cast are separated by "|".
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	"own1_mongodb/movies"
+)
+
+// Format is a file format.
+type Format string
+
+const (
+	JSONLines Format = "jsonl"
+	ExtJSON   Format = "extjson"
+	CSV       Format = "csv"
+)
+
+var csvHeader = []string{"title", "year", "genres", "cast", "rating", "votes"}
+
+// FormatFromPath guesses the format from the file extension: .csv is CSV,
+// .extjson and .json are extended JSON and anything else is JSON Lines.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSV
+	case ".extjson", ".json":
+		return ExtJSON
+	default:
+		return JSONLines
+	}
+}
+
+// ParseFormat checks that s names a known format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case JSONLines, ExtJSON, CSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected jsonl, extjson or csv", s)
+}
+
+// Reader reads documents one at a time. Next returns io.EOF after the last
+// one.
+type Reader interface {
+	Next() (bson.D, error)
+}
+
+// NewReader returns a Reader for r in format f.
+func NewReader(f Format, r io.Reader) (Reader, error) {
+	switch f {
+	case JSONLines, ExtJSON:
+		// plain JSON is valid relaxed extended JSON, so both formats can
+		// be parsed the same way
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), 16<<20)
+		return &jsonReader{scanner: scanner}, nil
+	case CSV:
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV header: %w", err)
+		}
+		return &csvReader{r: cr, columns: columnIndex(header)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", f)
+}
+
+type jsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (jr *jsonReader) Next() (bson.D, error) {
+	for jr.scanner.Scan() {
+		jr.line++
+		line := bytes.TrimSpace(jr.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(line, false, &doc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", jr.line, err)
+		}
+		return doc, nil
+	}
+	if err := jr.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func columnIndex(header []string) map[string]int {
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	return columns
+}
+
+func (cr *csvReader) Next() (bson.D, error) {
+	record, err := cr.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	field := func(name string) string {
+		if i, ok := cr.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	line, _ := cr.r.FieldPos(0)
+
+	doc := bson.D{{Key: "title", Value: field("title")}}
+	if year := field("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: year %q: %w", line, year, err)
+		}
+		doc = append(doc, bson.E{Key: "year", Value: int32(y)})
+	}
+	if genres := field("genres"); genres != "" {
+		doc = append(doc, bson.E{Key: "genres", Value: strings.Split(genres, "|")})
+	}
+	if cast := field("cast"); cast != "" {
+		doc = append(doc, bson.E{Key: "cast", Value: strings.Split(cast, "|")})
+	}
+	imdb := bson.D{}
+	if rating := field("rating"); rating != "" {
+		r, err := strconv.ParseFloat(rating, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: rating %q: %w", line, rating, err)
+		}
+		imdb = append(imdb, bson.E{Key: "rating", Value: r})
+	}
+	if votes := field("votes"); votes != "" {
+		v, err := strconv.Atoi(votes)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: votes %q: %w", line, votes, err)
+		}
+		imdb = append(imdb, bson.E{Key: "votes", Value: int32(v)})
+	}
+	if len(imdb) > 0 {
+		doc = append(doc, bson.E{Key: "imdb", Value: imdb})
+	}
+	return doc, nil
+}
+
+// Writer writes documents in a format. Close flushes buffered output.
+type Writer interface {
+	Write(doc bson.Raw) error
+	Close() error
+}
+
+// NewWriter returns a Writer that writes to w in format f.
+func NewWriter(f Format, w io.Writer) (Writer, error) {
+	switch f {
+	case JSONLines:
+		return &jsonWriter{w: bufio.NewWriter(w)}, nil
+	case ExtJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), canonical: true}, nil
+	case CSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", f)
+}
+
+type jsonWriter struct {
+	w         *bufio.Writer
+	canonical bool
+}
+
+func (jw *jsonWriter) Write(doc bson.Raw) error {
+	data, err := bson.MarshalExtJSON(doc, jw.canonical, false)
+	if err != nil {
+		return err
+	}
+	jw.w.Write(data)
+	return jw.w.WriteByte('\n')
+}
+
+func (jw *jsonWriter) Close() error {
+	return jw.w.Flush()
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// Write builds the row from the raw document instead of decoding it into a
+// movies.Movie, so a field of an unexpected type only leaves its column
+// empty instead of failing the export.
+func (cw *csvWriter) Write(doc bson.Raw) error {
+	var ratings movies.Ratings
+	if imdb, err := doc.LookupErr("imdb"); err == nil {
+		if err := ratings.UnmarshalBSONValue(imdb.Type, imdb.Value); err != nil {
+			return fmt.Errorf("document %v: %w", doc.Lookup("_id"), err)
+		}
+	}
+	title, _ := doc.Lookup("title").StringValueOK()
+	row := []string{
+		title,
+		"",
+		strings.Join(stringArray(doc.Lookup("genres")), "|"),
+		strings.Join(stringArray(doc.Lookup("cast")), "|"),
+		"",
+		"",
+	}
+	if year, ok := integer(doc.Lookup("year")); ok {
+		row[1] = strconv.Itoa(year)
+	}
+	if ratings.Rating != 0 {
+		row[4] = strconv.FormatFloat(ratings.Rating, 'f', -1, 64)
+	}
+	if ratings.Votes != 0 {
+		row[5] = strconv.Itoa(ratings.Votes)
+	}
+	return cw.w.Write(row)
+}
+
+// integer returns v as an int if it's a number or a string holding one.
+func integer(v bson.RawValue) (int, bool) {
+	switch v.Type {
+	case bsontype.Int32:
+		return int(v.Int32()), true
+	case bsontype.Int64:
+		return int(v.Int64()), true
+	case bsontype.Double:
+		return int(v.Double()), true
+	case bsontype.String:
+		i, err := strconv.Atoi(strings.TrimSpace(v.StringValue()))
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// stringArray returns the strings of the array v, other elements are left
+// out.
+func stringArray(v bson.RawValue) []string {
+	arr, ok := v.ArrayOK()
+	if !ok {
+		return nil
+	}
+	values, err := arr.Values()
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, value := range values {
+		if s, ok := value.StringValueOK(); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,195 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errNoTitle = errors.New("document has no title")
+
+// DefaultBatchSize is the number of documents per BulkWrite.
+const DefaultBatchSize = 500
+
+// BulkWriter is the part of *mongo.Collection an Importer needs.
+type BulkWriter interface {
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+}
+
+// Progress is reported after every batch.
+type Progress struct {
+	// Read counts the documents read from the input, including the ones
+	// skipped because an earlier run already imported them.
+	Read     int
+	Inserted int
+	Updated  int
+}
+
+// Importer upserts documents by title in batches.
+type Importer struct {
+	Coll      BulkWriter
+	BatchSize int
+	// Progress, if set, is called after every batch.
+	Progress func(Progress)
+	// Checkpoint, if set, is a file that records how many documents were
+	// imported. An import that finds a checkpoint skips that many documents,
+	// so a failed import can be resumed. The file is removed once the
+	// import succeeded.
+	Checkpoint string
+}
+
+type checkpoint struct {
+	Imported int `json:"imported"`
+}
+
+// Import reads all documents from r and upserts them by title. Documents
+// that already exist keep their _id, all other fields are overwritten.
+func (im Importer) Import(ctx context.Context, r Reader) (Progress, error) {
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	skip, err := im.loadCheckpoint()
+	if err != nil {
+		return Progress{}, err
+	}
+
+	var p Progress
+	batch := make([]mongo.WriteModel, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := im.Coll.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return fmt.Errorf("writing documents %d to %d: %w", p.Read-len(batch)+1, p.Read, err)
+		}
+		p.Inserted += int(res.UpsertedCount)
+		p.Updated += int(res.ModifiedCount)
+		batch = batch[:0]
+		if err := im.saveCheckpoint(p.Read); err != nil {
+			return err
+		}
+		if im.Progress != nil {
+			im.Progress(p)
+		}
+		return nil
+	}
+
+	for {
+		doc, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return p, err
+		}
+		p.Read++
+		if p.Read <= skip {
+			continue
+		}
+		model, err := upsertByTitle(doc)
+		if err != nil {
+			return p, fmt.Errorf("document %d: %w", p.Read, err)
+		}
+		batch = append(batch, model)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return p, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return p, err
+	}
+	if im.Checkpoint != "" {
+		if err := os.Remove(im.Checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// upsertByTitle sets all fields of doc on the movie with the same title. The
+// _id of doc is only used if the movie doesn't exist yet, since the _id of
+// an existing document can't change.
+func upsertByTitle(doc bson.D) (mongo.WriteModel, error) {
+	var title any
+	set := bson.D{}
+	setOnInsert := bson.D{}
+	for _, e := range doc {
+		switch e.Key {
+		case "_id":
+			setOnInsert = append(setOnInsert, e)
+		case "title":
+			title = e.Value
+			set = append(set, e)
+		default:
+			set = append(set, e)
+		}
+	}
+	if s, ok := title.(string); !ok || s == "" {
+		return nil, errNoTitle
+	}
+	update := bson.D{{Key: "$set", Value: set}}
+	if len(setOnInsert) > 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: setOnInsert})
+	}
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.D{{Key: "title", Value: title}}).
+		SetUpdate(update).
+		SetUpsert(true), nil
+}
+
+func (im Importer) loadCheckpoint() (int, error) {
+	if im.Checkpoint == "" {
+		return 0, nil
+	}
+	data, err := os.ReadFile(im.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return 0, fmt.Errorf("checkpoint %s: %w", im.Checkpoint, err)
+	}
+	return c.Imported, nil
+}
+
+func (im Importer) saveCheckpoint(imported int) error {
+	if im.Checkpoint == "" {
+		return nil
+	}
+	data, err := json.Marshal(checkpoint{Imported: imported})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(im.Checkpoint, data, 0o644)
+}
+
+// Export writes every document of cursor to w and returns how many it
+// wrote. It closes the cursor.
+func Export(ctx context.Context, cursor *mongo.Cursor, w Writer) (int, error) {
+	defer cursor.Close(ctx)
+	n := 0
+	for cursor.Next(ctx) {
+		if err := w.Write(cursor.Current); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := cursor.Err(); err != nil {
+		return n, err
+	}
+	return n, w.Close()
+}
@@ -0,0 +1,215 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fakeColl records the batches it's given and fails the batch number
+// failOn, counting from 1.
+type fakeColl struct {
+	batches [][]mongo.WriteModel
+	failOn  int
+}
+
+func (fc *fakeColl) BulkWrite(_ context.Context, models []mongo.WriteModel, _ ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	if len(fc.batches)+1 == fc.failOn {
+		fc.failOn = 0
+		return nil, errors.New("connection lost")
+	}
+	fc.batches = append(fc.batches, append([]mongo.WriteModel{}, models...))
+	return &mongo.BulkWriteResult{UpsertedCount: int64(len(models))}, nil
+}
+
+func titlesOf(batches [][]mongo.WriteModel) []string {
+	var titles []string
+	for _, b := range batches {
+		for _, m := range b {
+			filter := m.(*mongo.UpdateOneModel).Filter.(bson.D)
+			titles = append(titles, filter[0].Value.(string))
+		}
+	}
+	return titles
+}
+
+const sampleJSONL = `{"title": "Alien", "year": 1979}
+{"title": "Aliens", "year": 1986}
+
+{"title": "Jaws", "year": 1975}
+{"title": "Ghostbusters", "year": 1984}
+{"title": "Heat", "year": 1995}
+`
+
+func TestImportBatches(t *testing.T) {
+	coll := &fakeColl{}
+	r, _ := NewReader(JSONLines, strings.NewReader(sampleJSONL))
+	var reports []Progress
+	p, err := Importer{Coll: coll, BatchSize: 2, Progress: func(p Progress) {
+		reports = append(reports, p)
+	}}.Import(context.Background(), r)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(coll.batches) != 3 || len(reports) != 3 {
+		t.Error("expected 3 batches and reports, got", len(coll.batches), len(reports))
+	}
+	if p.Read != 5 || p.Inserted != 5 {
+		t.Errorf("unexpected progress %+v", p)
+	}
+}
+
+func TestImportResume(t *testing.T) {
+	checkpointPath := filepath.Join(t.TempDir(), "import.checkpoint")
+	coll := &fakeColl{failOn: 2}
+	im := Importer{Coll: coll, BatchSize: 2, Checkpoint: checkpointPath}
+
+	r, _ := NewReader(JSONLines, strings.NewReader(sampleJSONL))
+	if _, err := im.Import(context.Background(), r); err == nil {
+		t.Fatal("expected the second batch to fail")
+	}
+
+	r, _ = NewReader(JSONLines, strings.NewReader(sampleJSONL))
+	if _, err := im.Import(context.Background(), r); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := "Alien,Aliens,Jaws,Ghostbusters,Heat"
+	if got := strings.Join(titlesOf(coll.batches), ","); got != expected {
+		t.Error("expected", expected, "got", got)
+	}
+	if _, err := os.Stat(checkpointPath); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected the checkpoint to be removed, got", err)
+	}
+}
+
+func TestUpsertByTitleKeepsExistingID(t *testing.T) {
+	r, _ := NewReader(ExtJSON, strings.NewReader(`{"_id": {"$oid": "573a1390f29313caabcd4135"}, "title": "Blacksmith Scene", "year": {"$numberInt": "1893"}}`))
+	doc, err := r.Next()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	model, err := upsertByTitle(doc)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	update := model.(*mongo.UpdateOneModel).Update.(bson.D)
+	if update[0].Key != "$set" || update[1].Key != "$setOnInsert" {
+		t.Error("unexpected update", update)
+	}
+	if _, err := upsertByTitle(bson.D{{Key: "year", Value: 1}}); !errors.Is(err, errNoTitle) {
+		t.Error("expected errNoTitle, got", err)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	input := "title,year,genres,cast,rating,votes\n" +
+		"Alien,1979,Horror|Sci-Fi,Sigourney Weaver|Tom Skerritt,8.5,600000\n" +
+		"Untitled,,,,,\n"
+	r, err := NewReader(CSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(CSV, &buf)
+	for {
+		doc, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		raw, _ := bson.Marshal(doc)
+		if err := w.Write(raw); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	w.Close()
+
+	if buf.String() != input {
+		t.Errorf("expected\n%s\ngot\n%s", input, buf.String())
+	}
+}
+
+func TestCSVBadYear(t *testing.T) {
+	r, _ := NewReader(CSV, strings.NewReader("title,year\nAlien,soon\n"))
+	if _, err := r.Next(); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Error("expected an error for line 2, got", err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	docs := []any{
+		bson.D{{Key: "title", Value: "Alien"}, {Key: "year", Value: int32(1979)}},
+		bson.D{{Key: "title", Value: "Jaws"}, {Key: "year", Value: int32(1975)}},
+	}
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, _ := NewWriter(ExtJSON, &buf)
+	n, err := Export(context.Background(), cursor, w)
+	if err != nil || n != 2 {
+		t.Fatal("expected 2 documents, got", n, err)
+	}
+	expected := `{"title":"Alien","year":{"$numberInt":"1979"}}` + "\n" +
+		`{"title":"Jaws","year":{"$numberInt":"1975"}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestExportCSVLooseTypes(t *testing.T) {
+	docs := []any{
+		bson.D{
+			{Key: "title", Value: "Alien"},
+			{Key: "year", Value: int32(1979)},
+			{Key: "genres", Value: bson.A{"Horror", "Sci-Fi"}},
+			{Key: "imdb", Value: bson.D{{Key: "rating", Value: 8.5}, {Key: "votes", Value: int32(1000)}}},
+		},
+		// like some documents of the sample data
+		bson.D{
+			{Key: "title", Value: "Obscure"},
+			{Key: "year", Value: "1995\u00e8"},
+			{Key: "imdb", Value: bson.D{{Key: "rating", Value: ""}, {Key: "votes", Value: "1,234"}}},
+		},
+	}
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, _ := NewWriter(CSV, &buf)
+	n, err := Export(context.Background(), cursor, w)
+	if err != nil || n != 2 {
+		t.Fatal("expected 2 documents, got", n, err)
+	}
+	expected := "title,year,genres,cast,rating,votes\n" +
+		"Alien,1979,Horror|Sci-Fi,,8.5,1000\n" +
+		"Obscure,,,,,1234\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	data := map[string]Format{"a.csv": CSV, "a.json": ExtJSON, "a.jsonl": JSONLines, "a": JSONLines}
+	for path, expected := range data {
+		if got := FormatFromPath(path); got != expected {
+			t.Error(path, "expected", expected, "got", got)
+		}
+	}
+}
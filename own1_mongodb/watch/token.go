@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TokenStore persists the resume token of the last handled change.
+type TokenStore interface {
+	// Load returns the saved token, nil if there is none.
+	Load() (bson.Raw, error)
+	Save(token bson.Raw) error
+}
+
+// FileTokenStore keeps the resume token in a file.
+type FileTokenStore struct {
+	Path string
+}
+
+func (s FileTokenStore) Load() (bson.Raw, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := bson.Raw(data)
+	if err := token.Validate(); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Save writes token to a temporary file and renames it, so a crash never
+// leaves a half written token behind.
+func (s FileTokenStore) Save(token bson.Raw) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(token); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
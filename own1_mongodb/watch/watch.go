@@ -0,0 +1,128 @@
+// Package watch tails a Mongo change stream and forwards the changes to
+// sinks. Change streams need a replica set, which is why docker-compose
+// starts mongod with --replSet. The resume token of the last forwarded
+// change is persisted, so a restarted watcher continues where it stopped
+// instead of missing or repeating changes.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Event is a single change, in a form that can be written as JSON. The
+// documents are Mongo relaxed extended JSON.
+type Event struct {
+	Operation     string          `json:"operation"`
+	Collection    string          `json:"collection"`
+	DocumentID    string          `json:"documentId"`
+	Document      json.RawMessage `json:"document,omitempty"`
+	UpdatedFields json.RawMessage `json:"updatedFields,omitempty"`
+	RemovedFields []string        `json:"removedFields,omitempty"`
+	Time          time.Time       `json:"time"`
+}
+
+// changeEvent is the part of a change stream document we use.
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	NS            struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID any `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument      bson.Raw `bson:"fullDocument"`
+	UpdateDescription struct {
+		UpdatedFields bson.Raw `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+	ClusterTime primitive.Timestamp `bson:"clusterTime"`
+}
+
+func toEvent(raw bson.Raw) (Event, error) {
+	var ce changeEvent
+	if err := bson.Unmarshal(raw, &ce); err != nil {
+		return Event{}, err
+	}
+	e := Event{
+		Operation:     ce.OperationType,
+		Collection:    ce.NS.Coll,
+		DocumentID:    idString(ce.DocumentKey.ID),
+		RemovedFields: ce.UpdateDescription.RemovedFields,
+		Time:          time.Unix(int64(ce.ClusterTime.T), 0).UTC(),
+	}
+	var err error
+	if e.Document, err = toJSON(ce.FullDocument); err != nil {
+		return Event{}, err
+	}
+	if e.UpdatedFields, err = toJSON(ce.UpdateDescription.UpdatedFields); err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+func toJSON(raw bson.Raw) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return bson.MarshalExtJSON(raw, false, false)
+}
+
+func idString(id any) string {
+	if oid, ok := id.(primitive.ObjectID); ok {
+		return oid.Hex()
+	}
+	return fmt.Sprint(id)
+}
+
+// Watch forwards inserts, updates, replaces and deletes on coll to sink until
+// ctx is done. It resumes after the token in store, if there is one, and
+// saves the token of every change once sink accepted it. If the sink fails,
+// Watch stops and returns the error; the change is sent again on the next
+// run.
+func Watch(ctx context.Context, coll *mongo.Collection, store TokenStore, sink Sink) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "operationType", Value: bson.D{
+			{Key: "$in", Value: bson.A{"insert", "update", "replace", "delete"}},
+		}}}}},
+	}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	token, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if token != nil {
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := coll.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		e, err := toEvent(stream.Current)
+		if err != nil {
+			return err
+		}
+		if err := sink.Send(ctx, e); err != nil {
+			return fmt.Errorf("sending %s of %s: %w", e.Operation, e.DocumentID, err)
+		}
+		if err := store.Save(stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	if err := stream.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestToEvent(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(bson.D{
+		{Key: "operationType", Value: "update"},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "sample_mflix"}, {Key: "coll", Value: "movies"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: id}}},
+		{Key: "fullDocument", Value: bson.D{{Key: "_id", Value: id}, {Key: "title", Value: "Jaws"}}},
+		{Key: "updateDescription", Value: bson.D{
+			{Key: "updatedFields", Value: bson.D{{Key: "year", Value: int32(1975)}}},
+			{Key: "removedFields", Value: bson.A{"cast"}},
+		}},
+		{Key: "clusterTime", Value: primitive.Timestamp{T: 1700000000, I: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := toEvent(raw)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if e.Operation != "update" || e.Collection != "movies" || e.DocumentID != id.Hex() {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if string(e.UpdatedFields) != `{"year":1975}` {
+		t.Error("unexpected updated fields", string(e.UpdatedFields))
+	}
+	if !strings.Contains(string(e.Document), `"title":"Jaws"`) {
+		t.Error("unexpected document", string(e.Document))
+	}
+	if e.Time.Unix() != 1700000000 {
+		t.Error("unexpected time", e.Time)
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	store := FileTokenStore{Path: filepath.Join(t.TempDir(), "token")}
+	token, err := store.Load()
+	if err != nil || token != nil {
+		t.Fatal("expected no token, got", token, err)
+	}
+
+	saved, _ := bson.Marshal(bson.D{{Key: "_data", Value: "8265"}})
+	if err := store.Save(saved); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	token, err = store.Load()
+	if err != nil || !bytes.Equal(token, saved) {
+		t.Error("expected the saved token, got", token, err)
+	}
+}
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(&buf)
+	sink.Send(context.Background(), Event{Operation: "delete", DocumentID: "1"})
+	sink.Send(context.Background(), Event{Operation: "insert", DocumentID: "2", Document: json.RawMessage(`{"a":1}`)})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatal("expected 2 lines, got", lines)
+	}
+	if strings.Contains(lines[0], "document\"") {
+		t.Error("expected no document for a delete, got", lines[0])
+	}
+	if !strings.Contains(lines[1], `"document":{"a":1}`) {
+		t.Error("expected the document, got", lines[1])
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	err := WebhookSink{URL: server.URL}.Send(context.Background(), Event{Operation: "insert", DocumentID: "42"})
+	if err != nil || received.DocumentID != "42" {
+		t.Error("expected the event to arrive, got", received, err)
+	}
+	if err := (WebhookSink{URL: server.URL + "/fail"}).Send(context.Background(), Event{}); err == nil {
+		t.Error("expected an error for a 500")
+	}
+}
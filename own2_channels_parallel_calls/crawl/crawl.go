@@ -0,0 +1,389 @@
+// Package crawl crawls a web site breadth-first on top of the fetch package.
+// It follows links found in HTML pages up to a maximum depth, visits every
+// URL only once, respects robots.txt and waits between two requests to the
+// same host. The result is a Report that can be written as JSON or as a
+// sitemap.
+package crawl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"own2/fetch"
+)
+
+// Page is a single crawled URL.
+type Page struct {
+	URL    string   `json:"url"`
+	Depth  int      `json:"depth"`
+	Status int      `json:"status,omitempty"`
+	Links  []string `json:"links,omitempty"`
+	Err    string   `json:"error,omitempty"`
+}
+
+// Report lists the crawled pages in the order they were visited.
+type Report struct {
+	Start string `json:"start"`
+	Pages []Page `json:"pages"`
+}
+
+// WriteJSON writes r as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteSitemap writes the successfully fetched pages of r in the
+// sitemaps.org XML format.
+func (r Report) WriteSitemap(w io.Writer) error {
+	type loc struct {
+		Loc string `xml:"loc"`
+	}
+	sitemap := struct {
+		XMLName xml.Name `xml:"urlset"`
+		NS      string   `xml:"xmlns,attr"`
+		URLs    []loc    `xml:"url"`
+	}{NS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, p := range r.Pages {
+		if p.Err == "" && p.Status >= 200 && p.Status < 300 {
+			sitemap.URLs = append(sitemap.URLs, loc{p.URL})
+		}
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(sitemap); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Crawler crawls sites. Create one with New.
+type Crawler struct {
+	fetcher     *fetch.Fetcher
+	maxDepth    int
+	delay       time.Duration
+	sameHost    bool
+	checkRobots bool
+	userAgent   string
+	maxParallel int
+}
+
+// Option configures a Crawler.
+type Option func(*Crawler)
+
+// WithMaxDepth sets how many links away from the start page the crawler
+// goes. The start page has depth 0.
+func WithMaxDepth(n int) Option {
+	return func(c *Crawler) {
+		c.maxDepth = n
+	}
+}
+
+// WithDelay sets the minimum time between two requests to the same host.
+func WithDelay(d time.Duration) Option {
+	return func(c *Crawler) {
+		c.delay = d
+	}
+}
+
+// WithSameHost restricts the crawl to the host of the start page.
+func WithSameHost(sameHost bool) Option {
+	return func(c *Crawler) {
+		c.sameHost = sameHost
+	}
+}
+
+// WithRobots enables or disables robots.txt checks.
+func WithRobots(robots bool) Option {
+	return func(c *Crawler) {
+		c.checkRobots = robots
+	}
+}
+
+// WithUserAgent sets the user agent whose robots.txt rules are followed.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Crawler) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithMaxParallel caps how many hosts are crawled at the same time.
+func WithMaxParallel(n int) Option {
+	return func(c *Crawler) {
+		c.maxParallel = n
+	}
+}
+
+// New returns a Crawler that uses f for its requests. By default it stays on
+// the start host, goes 2 levels deep, respects robots.txt and waits 500ms
+// between requests to the same host.
+func New(f *fetch.Fetcher, opts ...Option) *Crawler {
+	c := &Crawler{
+		fetcher:     f,
+		maxDepth:    2,
+		delay:       500 * time.Millisecond,
+		sameHost:    true,
+		checkRobots: true,
+		userAgent:   "own2-crawler",
+		maxParallel: 4,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	c.maxParallel = max(c.maxParallel, 1)
+	return c
+}
+
+// Crawl crawls breadth-first starting at start. A page that can't be
+// fetched ends up in the report with its error, it doesn't stop the crawl.
+// Crawl only fails if start isn't a valid URL or ctx is done.
+func (c *Crawler) Crawl(ctx context.Context, start string) (Report, error) {
+	startURL, err := url.Parse(start)
+	if err != nil {
+		return Report{}, err
+	}
+	startURL.Fragment = ""
+
+	s := newState(c, startURL)
+	report := Report{Start: startURL.String()}
+	level := []*url.URL{startURL}
+	for depth := 0; len(level) > 0 && depth <= c.maxDepth; depth++ {
+		pages := s.crawlLevel(ctx, level, depth)
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		level = nil
+		for _, p := range pages {
+			report.Pages = append(report.Pages, p.Page)
+			level = append(level, p.next...)
+		}
+	}
+	return report, nil
+}
+
+// state is what a single Crawl call keeps track of.
+type state struct {
+	*Crawler
+	host string
+
+	mu      sync.Mutex
+	visited map[string]bool
+	robots  map[string]robots
+	// last is when the previous request to a host was sent
+	last map[string]time.Time
+}
+
+func newState(c *Crawler, start *url.URL) *state {
+	return &state{
+		Crawler: c,
+		visited: map[string]bool{start.String(): true},
+		robots:  map[string]robots{},
+		last:    map[string]time.Time{},
+		host:    start.Host,
+	}
+}
+
+type crawledPage struct {
+	Page
+	// next are the links that haven't been visited yet
+	next []*url.URL
+}
+
+// crawlLevel fetches all urls of one depth. URLs of the same host are
+// fetched one after another to honor the delay, different hosts are
+// crawled concurrently.
+func (s *state) crawlLevel(ctx context.Context, urls []*url.URL, depth int) []crawledPage {
+	pages := make([]crawledPage, len(urls))
+	byHost := map[string][]int{}
+	var hosts []string
+	for i, u := range urls {
+		if _, ok := byHost[u.Host]; !ok {
+			hosts = append(hosts, u.Host)
+		}
+		byHost[u.Host] = append(byHost[u.Host], i)
+	}
+
+	sem := make(chan struct{}, s.maxParallel)
+	var wg sync.WaitGroup
+	wg.Add(len(hosts))
+	for _, host := range hosts {
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			for _, i := range byHost[host] {
+				pages[i] = s.crawlPage(ctx, urls[i], depth)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// drop the pages robots.txt didn't allow us to fetch
+	crawled := pages[:0]
+	for _, p := range pages {
+		if p.URL != "" {
+			crawled = append(crawled, p)
+		}
+	}
+	return crawled
+}
+
+func (s *state) crawlPage(ctx context.Context, u *url.URL, depth int) crawledPage {
+	if s.checkRobots && !s.robotsFor(ctx, u).allowedURL(u) {
+		return crawledPage{}
+	}
+	if err := s.wait(ctx, u.Host); err != nil {
+		return crawledPage{Page: Page{URL: u.String(), Depth: depth, Err: err.Error()}}
+	}
+
+	r := s.fetcher.Get(ctx, u.String())
+	page := crawledPage{Page: Page{URL: u.String(), Depth: depth, Status: r.Status}}
+	if r.Err != nil {
+		page.Err = r.Err.Error()
+		return page
+	}
+	if !isHTML(r) {
+		// error pages, images, JSON and the like aren't searched for links
+		return page
+	}
+	for _, link := range extractLinks(u, r.Body) {
+		page.Links = append(page.Links, link.String())
+		if depth < s.maxDepth && s.follow(link) {
+			page.next = append(page.next, link)
+		}
+	}
+	return page
+}
+
+// follow reports whether link should be crawled and marks it visited.
+func (s *state) follow(link *url.URL) bool {
+	if s.sameHost && link.Host != s.host {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.visited[link.String()] {
+		return false
+	}
+	s.visited[link.String()] = true
+	return true
+}
+
+// wait blocks until the delay since the last request to host is over.
+func (s *state) wait(ctx context.Context, host string) error {
+	s.mu.Lock()
+	next := s.last[host].Add(s.delay)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	s.last[host] = next
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(next)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// robotsFor returns the robots.txt rules of u's host, fetching them on
+// first use. Following RFC 9309 a missing robots.txt (4xx) allows
+// everything, while an unreachable one (network error or 5xx) disallows
+// everything. The latter isn't cached, the next URL of the host tries again.
+func (s *state) robotsFor(ctx context.Context, u *url.URL) robots {
+	s.mu.Lock()
+	r, ok := s.robots[u.Host]
+	s.mu.Unlock()
+	if ok {
+		return r
+	}
+
+	disallowAll := robots{disallow: []string{"/"}}
+	if err := s.wait(ctx, u.Host); err != nil {
+		return disallowAll
+	}
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	res := s.fetcher.Get(ctx, robotsURL.String())
+	switch {
+	case res.Err != nil || res.Status >= 500:
+		return disallowAll
+	case res.Status >= 200 && res.Status < 300:
+		r = parseRobots(string(res.Body), s.userAgent)
+	}
+
+	s.mu.Lock()
+	s.robots[u.Host] = r
+	s.mu.Unlock()
+	return r
+}
+
+// isHTML reports whether r is a successful response with an HTML body.
+func isHTML(r fetch.Result) bool {
+	if r.Status < 200 || r.Status >= 300 {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "text/html"
+}
+
+// extractLinks returns the absolute http(s) URLs of all <a href> in body,
+// without fragments and duplicates.
+func extractLinks(base *url.URL, body []byte) []*url.URL {
+	var links []*url.URL
+	seen := map[string]bool{}
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return links
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		if string(name) != "a" || !hasAttr {
+			continue
+		}
+		for {
+			key, val, more := z.TagAttr()
+			if string(key) == "href" {
+				if link, ok := resolve(base, string(val)); ok && !seen[link.String()] {
+					seen[link.String()] = true
+					links = append(links, link)
+				}
+			}
+			if !more {
+				break
+			}
+		}
+	}
+}
+
+func resolve(base *url.URL, href string) (*url.URL, bool) {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil, false
+	}
+	link := base.ResolveReference(ref)
+	if link.Scheme != "http" && link.Scheme != "https" {
+		return nil, false
+	}
+	link.Fragment = ""
+	return link, true
+}
@@ -0,0 +1,274 @@
+package crawl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"own2/fetch"
+)
+
+// site is a small tree of pages:
+//
+//	/ -> /a, /b, external
+//	/a -> /a/deep, / (cycle)
+//	/b -> /private/x
+//	/a/deep -> /a/deeper
+var site = map[string]string{
+	"/":          `<a href="/a">a</a> <a href="b#top">b</a> <a href="https://example.com/">ext</a>`,
+	"/a":         `<a href="/a/deep">deep</a><a href="/">home</a>`,
+	"/b":         `<a href="/private/x">secret</a>`,
+	"/a/deep":    `<a href="/a/deeper">deeper</a>`,
+	"/a/deeper":  `nothing here`,
+	"/private/x": `should never be fetched`,
+}
+
+type recordingHandler struct {
+	mu       sync.Mutex
+	requests []string
+	times    []time.Time
+}
+
+func (h *recordingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests = append(h.requests, r.URL.Path)
+	h.times = append(h.times, time.Now())
+	h.mu.Unlock()
+
+	if r.URL.Path == "/robots.txt" {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		return
+	}
+	body, ok := site[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprint(w, "<html><body>"+body+"</body></html>")
+}
+
+func pagePaths(server *httptest.Server, r Report) []string {
+	var paths []string
+	for _, p := range r.Pages {
+		paths = append(paths, strings.TrimPrefix(p.URL, server.URL))
+	}
+	return paths
+}
+
+func TestCrawl(t *testing.T) {
+	h := &recordingHandler{}
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	c := New(fetch.New(), WithMaxDepth(2), WithDelay(0))
+	report, err := c.Crawl(context.Background(), server.URL+"/")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []string{"/", "/a", "/b", "/a/deep"}
+	if paths := pagePaths(server, report); !slices.Equal(expected, paths) {
+		t.Error("expected", expected, "got", paths)
+	}
+	for _, path := range h.requests {
+		if strings.HasPrefix(path, "/private") {
+			t.Error("fetched disallowed path", path)
+		}
+	}
+	if report.Pages[1].Depth != 1 || report.Pages[3].Depth != 2 {
+		t.Error("unexpected depths", report.Pages)
+	}
+}
+
+func TestCrawlPoliteness(t *testing.T) {
+	h := &recordingHandler{}
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	delay := 20 * time.Millisecond
+	c := New(fetch.New(), WithMaxDepth(1), WithDelay(delay), WithRobots(false))
+	if _, err := c.Crawl(context.Background(), server.URL+"/"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	for i := 1; i < len(h.times); i++ {
+		// allow for a little timer slack
+		if gap := h.times[i].Sub(h.times[i-1]); gap < delay-2*time.Millisecond {
+			t.Error("requests only", gap, "apart")
+		}
+	}
+}
+
+func TestReportWriters(t *testing.T) {
+	r := Report{
+		Start: "http://localhost/",
+		Pages: []Page{
+			{URL: "http://localhost/", Status: 200},
+			{URL: "http://localhost/missing", Status: 404},
+		},
+	}
+	var buf bytes.Buffer
+	if err := r.WriteSitemap(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<loc>http://localhost/</loc>") || strings.Contains(buf.String(), "missing") {
+		t.Error("unexpected sitemap", buf.String())
+	}
+
+	buf.Reset()
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"status": 404`) {
+		t.Error("unexpected JSON", buf.String())
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	r := parseRobots(`
+# comment
+User-agent: other
+Disallow: /
+
+User-agent: own2-crawler
+User-agent: friend
+Disallow: /private
+Allow: /private/public
+`, "own2-crawler")
+
+	data := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/private", false},
+		{"/private/x", false},
+		{"/private/public/x", true},
+	}
+	for _, d := range data {
+		if got := r.allowed(d.path); got != d.allowed {
+			t.Errorf("%s: expected %v, got %v", d.path, d.allowed, got)
+		}
+	}
+}
+
+func TestRobotsAllowedURL(t *testing.T) {
+	r := parseRobots(`
+User-agent: *
+Disallow: /search?private
+Disallow: /admin
+`, "own2-crawler")
+	root := parseRobots("User-agent: *\nDisallow: /\n", "own2-crawler")
+
+	data := []struct {
+		name    string
+		robots  robots
+		url     string
+		allowed bool
+	}{
+		{"root without slash", root, "http://example.com", false},
+		{"root with slash", root, "http://example.com/", false},
+		{"query rule", r, "http://example.com/search?private=1", false},
+		{"other query", r, "http://example.com/search?q=go", true},
+		{"no query", r, "http://example.com/search", true},
+		{"query on disallowed path", r, "http://example.com/admin?x=1", false},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			u, err := url.Parse(d.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.robots.allowedURL(u); got != d.allowed {
+				t.Errorf("%s: expected %v, got %v", d.url, d.allowed, got)
+			}
+		})
+	}
+}
+
+func TestRobotsForUnreachable(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if robotsRequests.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/")
+	s := newState(New(fetch.New(), WithDelay(0)), u)
+	if s.robotsFor(context.Background(), u).allowedURL(u) {
+		t.Error("expected an unreachable robots.txt to disallow everything")
+	}
+	r := s.robotsFor(context.Background(), u)
+	if !r.allowed("/") || r.allowed("/private") {
+		t.Error("expected the rules of the second fetch, got", r)
+	}
+	if n := robotsRequests.Load(); n != 2 {
+		t.Error("expected robots.txt to be fetched again after the failure, got", n, "requests")
+	}
+}
+
+func TestRobotsForMissing(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		robotsRequests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/private")
+	s := newState(New(fetch.New(), WithDelay(0)), u)
+	for i := 0; i < 2; i++ {
+		if !s.robotsFor(context.Background(), u).allowedURL(u) {
+			t.Error("expected a missing robots.txt to allow everything")
+		}
+	}
+	if n := robotsRequests.Load(); n != 1 {
+		t.Error("expected robots.txt to be fetched once, got", n, "requests")
+	}
+}
+
+func TestCrawlOnlyFollowsLinksOfHTMLPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, `<a href="/data.json">data</a><a href="/gone">gone</a>`)
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"html": "<a href=\"/from-json\">x</a>"}`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<a href="/from-error-page">home</a>`)
+		}
+	}))
+	defer server.Close()
+
+	c := New(fetch.New(), WithMaxDepth(3), WithDelay(0), WithRobots(false))
+	report, err := c.Crawl(context.Background(), server.URL+"/")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected := []string{"/", "/data.json", "/gone"}
+	if paths := pagePaths(server, report); !slices.Equal(expected, paths) {
+		t.Error("expected", expected, "got", paths)
+	}
+	for _, p := range report.Pages[1:] {
+		if len(p.Links) != 0 {
+			t.Error("expected no links extracted from", p.URL, "got", p.Links)
+		}
+	}
+}
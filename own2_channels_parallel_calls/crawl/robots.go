@@ -0,0 +1,95 @@
+package crawl
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+)
+
+// robots holds the rules from a robots.txt that apply to us. Only Allow and
+// Disallow are supported, the longest matching rule wins and Allow wins a
+// tie, as described in RFC 9309.
+type robots struct {
+	allow    []string
+	disallow []string
+}
+
+// parseRobots extracts the rules of the group for userAgent, falling back to
+// the "*" group if there is no group for userAgent.
+func parseRobots(body string, userAgent string) robots {
+	userAgent = strings.ToLower(userAgent)
+	groups := map[string]*robots{}
+	var current []*robots
+	inAgents := false
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share one group
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			agent := strings.ToLower(value)
+			if groups[agent] == nil {
+				groups[agent] = &robots{}
+			}
+			current = append(current, groups[agent])
+		case "allow", "disallow":
+			inAgents = false
+			if value == "" {
+				continue
+			}
+			for _, g := range current {
+				if key == "allow" {
+					g.allow = append(g.allow, value)
+				} else {
+					g.disallow = append(g.disallow, value)
+				}
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	if g, ok := groups[userAgent]; ok {
+		return *g
+	}
+	if g, ok := groups["*"]; ok {
+		return *g
+	}
+	return robots{}
+}
+
+// allowedURL matches the rules against the path and query of u, "/" for a
+// URL without a path.
+func (r robots) allowedURL(u *url.URL) bool {
+	return r.allowed(u.RequestURI())
+}
+
+func (r robots) allowed(path string) bool {
+	allowLen := longestPrefix(r.allow, path)
+	disallowLen := longestPrefix(r.disallow, path)
+	return disallowLen < 0 || allowLen >= disallowLen
+}
+
+// longestPrefix returns the length of the longest rule that is a prefix of
+// path, or -1 if none is.
+func longestPrefix(rules []string, path string) int {
+	longest := -1
+	for _, rule := range rules {
+		if strings.HasPrefix(path, rule) && len(rule) > longest {
+			longest = len(rule)
+		}
+	}
+	return longest
+}
@@ -0,0 +1,112 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// StatusError is returned by FetchAll for responses with an unexpected
+// status code.
+type StatusError struct {
+	URL    string
+	Status int
+}
+
+func (se StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d %s", se.URL, se.Status, http.StatusText(se.Status))
+}
+
+// Decoded is the outcome of fetching a URL and decoding its JSON body.
+type Decoded[T any] struct {
+	URL    string
+	Status int
+	Value  T
+	Err    error
+}
+
+type fetchAllConfig struct {
+	failFast bool
+	statuses []int
+}
+
+// FetchAllOption configures FetchAll.
+type FetchAllOption func(*fetchAllConfig)
+
+// FailFast makes FetchAll cancel all outstanding requests as soon as one
+// fails and return just that error.
+func FailFast() FetchAllOption {
+	return func(c *fetchAllConfig) {
+		c.failFast = true
+	}
+}
+
+// ExpectStatus sets the status codes that count as success. By default any
+// 2xx status does.
+func ExpectStatus(statuses ...int) FetchAllOption {
+	return func(c *fetchAllConfig) {
+		c.statuses = statuses
+	}
+}
+
+// FetchAll fetches urls concurrently with f and decodes each JSON response
+// into a T. The results are returned in the order of urls. By default all
+// requests run to completion and their errors are combined with
+// errors.Join; with FailFast the first error cancels the rest.
+func FetchAll[T any](ctx context.Context, f *Fetcher, urls []string, opts ...FetchAllOption) ([]Decoded[T], error) {
+	var cfg fetchAllConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Decoded[T], len(urls))
+	var once sync.Once
+	var firstErr error
+	f.getAll(ctx, urls, func(i int, r Result) {
+		results[i] = decode[T](r, cfg)
+		if results[i].Err != nil && cfg.failFast {
+			once.Do(func() {
+				firstErr = results[i].Err
+				cancel()
+			})
+		}
+	})
+
+	if cfg.failFast {
+		return results, firstErr
+	}
+	var errs []error
+	for _, r := range results {
+		errs = append(errs, r.Err)
+	}
+	return results, errors.Join(errs...)
+}
+
+func decode[T any](r Result, cfg fetchAllConfig) Decoded[T] {
+	d := Decoded[T]{URL: r.URL, Status: r.Status, Err: r.Err}
+	if d.Err != nil {
+		return d
+	}
+	if !cfg.expected(r.Status) {
+		d.Err = StatusError{URL: r.URL, Status: r.Status}
+		return d
+	}
+	if err := json.Unmarshal(r.Body, &d.Value); err != nil {
+		d.Err = fmt.Errorf("decoding response from %s: %w", r.URL, err)
+	}
+	return d
+}
+
+func (c fetchAllConfig) expected(status int) bool {
+	if len(c.statuses) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(c.statuses, status)
+}
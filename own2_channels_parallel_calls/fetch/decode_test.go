@@ -0,0 +1,100 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type post struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func newPostServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/posts/1":
+			fmt.Fprint(w, `{"id": 1, "title": "first"}`)
+		case "/posts/2":
+			fmt.Fprint(w, `{"id": 2, "title": "second"}`)
+		case "/broken":
+			fmt.Fprint(w, `{"id": `)
+		case "/slow":
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestFetchAll(t *testing.T) {
+	server := newPostServer()
+	defer server.Close()
+
+	urls := []string{server.URL + "/posts/2", server.URL + "/posts/1"}
+	results, err := FetchAll[post](context.Background(), New(), urls)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if results[0].URL != urls[0] || results[0].Value.Title != "second" {
+		t.Errorf("unexpected first result %+v", results[0])
+	}
+	if results[1].URL != urls[1] || results[1].Value.ID != 1 {
+		t.Errorf("unexpected second result %+v", results[1])
+	}
+}
+
+func TestFetchAllCollectsErrors(t *testing.T) {
+	server := newPostServer()
+	defer server.Close()
+
+	urls := []string{server.URL + "/posts/1", server.URL + "/missing", server.URL + "/broken"}
+	results, err := FetchAll[post](context.Background(), New(), urls)
+
+	var se StatusError
+	if !errors.As(err, &se) || se.Status != http.StatusNotFound {
+		t.Error("expected a 404 StatusError, got", err)
+	}
+	if results[0].Err != nil || results[0].Value.Title != "first" {
+		t.Errorf("expected first post to succeed, got %+v", results[0])
+	}
+	if results[2].Err == nil {
+		t.Error("expected a decoding error for /broken")
+	}
+}
+
+func TestFetchAllFailFast(t *testing.T) {
+	server := newPostServer()
+	defer server.Close()
+
+	start := time.Now()
+	urls := []string{server.URL + "/slow", server.URL + "/missing"}
+	results, err := FetchAll[post](context.Background(), New(), urls, FailFast())
+
+	var se StatusError
+	if !errors.As(err, &se) {
+		t.Error("expected the StatusError only, got", err)
+	}
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Error("expected the slow request to be cancelled, got", results[0].Err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("fail fast took", time.Since(start))
+	}
+}
+
+func TestFetchAllExpectStatus(t *testing.T) {
+	server := newPostServer()
+	defer server.Close()
+
+	_, err := FetchAll[post](context.Background(), New(), []string{server.URL + "/posts/1"},
+		ExpectStatus(http.StatusAccepted))
+	if !errors.As(err, new(StatusError)) {
+		t.Error("expected a StatusError, got", err)
+	}
+}
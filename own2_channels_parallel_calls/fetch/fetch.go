@@ -0,0 +1,186 @@
+// Package fetch fetches URLs concurrently. Every request gets its own
+// timeout, the number of requests in flight is capped, bodies are size
+// limited and failed requests are retried with exponential back-off.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBodyTooLarge is returned when a response body exceeds the configured
+// maximum size.
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// Result is the outcome of fetching a single URL. Status is 0 if no response
+// was received at all. Duration covers all attempts including back-off.
+type Result struct {
+	URL      string
+	Status   int
+	Header   http.Header
+	Body     []byte
+	Err      error
+	Duration time.Duration
+}
+
+// Fetcher fetches URLs. Create one with New, the zero value isn't usable.
+type Fetcher struct {
+	client      *http.Client
+	timeout     time.Duration
+	maxParallel int
+	maxBodySize int64
+	retries     int
+	backoff     time.Duration
+}
+
+// Option configures a Fetcher.
+type Option func(*Fetcher)
+
+// WithClient sets the http.Client used for requests.
+func WithClient(c *http.Client) Option {
+	return func(f *Fetcher) {
+		f.client = c
+	}
+}
+
+// WithTimeout sets how long a single attempt may take.
+func WithTimeout(d time.Duration) Option {
+	return func(f *Fetcher) {
+		f.timeout = d
+	}
+}
+
+// WithMaxParallel caps how many requests GetAll runs at once.
+func WithMaxParallel(n int) Option {
+	return func(f *Fetcher) {
+		f.maxParallel = n
+	}
+}
+
+// WithMaxBodySize caps how many bytes of a response body are read. Larger
+// bodies fail with ErrBodyTooLarge.
+func WithMaxBodySize(n int64) Option {
+	return func(f *Fetcher) {
+		f.maxBodySize = n
+	}
+}
+
+// WithRetries retries failed attempts up to n times. The first retry waits
+// backoff, every further one twice as long as the one before.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(f *Fetcher) {
+		f.retries = n
+		f.backoff = backoff
+	}
+}
+
+// New returns a Fetcher with a 10s timeout, at most 4 parallel requests,
+// a 10MB body limit and no retries, unless changed by opts.
+func New(opts ...Option) *Fetcher {
+	f := &Fetcher{
+		client:      http.DefaultClient,
+		timeout:     10 * time.Second,
+		maxParallel: 4,
+		maxBodySize: 10 << 20,
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	f.maxParallel = max(f.maxParallel, 1)
+	return f
+}
+
+// GetAll fetches all urls concurrently and returns their results in the
+// order of urls.
+func (f *Fetcher) GetAll(ctx context.Context, urls []string) []Result {
+	results := make([]Result, len(urls))
+	f.getAll(ctx, urls, func(i int, r Result) {
+		results[i] = r
+	})
+	return results
+}
+
+// getAll fetches urls concurrently, at most f.maxParallel at a time, and
+// hands the result for urls[i] to done as soon as it's there. done is called
+// concurrently. URLs whose turn comes after ctx is done aren't fetched, their
+// result carries ctx.Err().
+func (f *Fetcher) getAll(ctx context.Context, urls []string, done func(i int, r Result)) {
+	sem := make(chan struct{}, f.maxParallel)
+	var wg sync.WaitGroup
+	wg.Add(len(urls))
+	for i, url := range urls {
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				done(i, f.Get(ctx, url))
+			case <-ctx.Done():
+				done(i, Result{URL: url, Err: ctx.Err()})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Get fetches url. Transport errors, 429 and 5xx responses are retried if
+// retries are enabled. A response with any other status isn't an error,
+// check Result.Status.
+func (f *Fetcher) Get(ctx context.Context, url string) Result {
+	start := time.Now()
+	result := Result{URL: url}
+	backoff := f.backoff
+	for attempt := 0; ; attempt++ {
+		result.Status, result.Header, result.Body, result.Err = f.attempt(ctx, url)
+		if attempt == f.retries || !retryable(result.Status, result.Err) {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			result.Err = errors.Join(result.Err, ctx.Err())
+			result.Duration = time.Since(start)
+			return result
+		}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (f *Fetcher) attempt(ctx context.Context, url string) (int, http.Header, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// read one byte more than allowed to find out if the body is too large
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBodySize+1))
+	if err != nil {
+		return resp.StatusCode, resp.Header, nil, fmt.Errorf("reading response from %s: %w", url, err)
+	}
+	if int64(len(body)) > f.maxBodySize {
+		return resp.StatusCode, resp.Header, nil, fmt.Errorf("%s: %w (limit %d bytes)", url, ErrBodyTooLarge, f.maxBodySize)
+	}
+	return resp.StatusCode, resp.Header, body, nil
+}
+
+func retryable(status int, err error) bool {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return false
+	}
+	return err != nil || status == http.StatusTooManyRequests || status >= 500
+}
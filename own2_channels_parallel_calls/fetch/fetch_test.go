@@ -0,0 +1,134 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "body of "+r.URL.Path)
+	}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/a", server.URL + "/missing", server.URL + "/b"}
+	results := New().GetAll(context.Background(), urls)
+
+	if len(results) != 3 {
+		t.Fatal("expected 3 results, got", len(results))
+	}
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Error("expected results in input order, got", r.URL, "at", i)
+		}
+		if r.Err != nil {
+			t.Error("unexpected error:", r.Err)
+		}
+	}
+	if results[0].Status != http.StatusOK || string(results[0].Body) != "body of /a" {
+		t.Error("unexpected result", results[0].Status, string(results[0].Body))
+	}
+	if results[1].Status != http.StatusNotFound {
+		t.Error("expected 404, got", results[1].Status)
+	}
+}
+
+func TestGetAllMaxParallel(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = server.URL
+	}
+	New(WithMaxParallel(2)).GetAll(context.Background(), urls)
+
+	if maxInFlight.Load() > 2 {
+		t.Error("expected at most 2 requests in flight, got", maxInFlight.Load())
+	}
+}
+
+func TestGetTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	r := New(WithTimeout(10*time.Millisecond)).Get(context.Background(), server.URL)
+	if !errors.Is(r.Err, context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", r.Err)
+	}
+}
+
+func TestGetMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", 100))
+	}))
+	defer server.Close()
+
+	r := New(WithMaxBodySize(10)).Get(context.Background(), server.URL)
+	if !errors.Is(r.Err, ErrBodyTooLarge) {
+		t.Error("expected ErrBodyTooLarge, got", r.Err)
+	}
+
+	r = New(WithMaxBodySize(100)).Get(context.Background(), server.URL)
+	if r.Err != nil || len(r.Body) != 100 {
+		t.Error("expected 100 bytes, got", len(r.Body), r.Err)
+	}
+}
+
+func TestGetRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "finally")
+	}))
+	defer server.Close()
+
+	r := New(WithRetries(3, time.Millisecond)).Get(context.Background(), server.URL)
+	if r.Status != http.StatusOK || string(r.Body) != "finally" {
+		t.Error("expected 200 finally, got", r.Status, string(r.Body))
+	}
+	if calls.Load() != 3 {
+		t.Error("expected 3 calls, got", calls.Load())
+	}
+}
+
+func TestGetNoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	r := New(WithRetries(3, time.Millisecond)).Get(context.Background(), server.URL)
+	if r.Status != http.StatusBadRequest || calls.Load() != 1 {
+		t.Error("expected a single 400, got", r.Status, calls.Load())
+	}
+}
@@ -1,3 +1,5 @@
 module own2
 
 go 1.22.5
+
+require golang.org/x/net v0.28.0
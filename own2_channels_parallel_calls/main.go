@@ -1,49 +1,70 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
-	"sync"
+	"log"
+	"os"
+	"time"
+
+	"own2/crawl"
+	"own2/fetch"
 )
 
-func fetchUrl(url string, wg *sync.WaitGroup, ch chan<- string) {
-	defer wg.Done()
-	resp, err := http.Get(url)
+func main() {
+	start := flag.String("crawl", "", "crawl the site starting at this URL instead of fetching the posts")
+	depth := flag.Int("depth", 2, "how many links deep to crawl")
+	delay := flag.Duration("delay", 500*time.Millisecond, "minimum time between two requests to the same host")
+	sitemap := flag.Bool("sitemap", false, "print the crawl report as sitemap XML instead of JSON")
+	flag.Parse()
 
-	if err != nil {
-		ch <- fmt.Sprintf("Error fetching %s: %w", url, err)
-	}
-	defer resp.Body.Close()
+	f := fetch.New(
+		fetch.WithTimeout(5*time.Second),
+		fetch.WithMaxParallel(2),
+		fetch.WithRetries(2, 200*time.Millisecond),
+	)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		ch <- fmt.Sprintf("Error reading respone from %s: %s", url, string(body))
+	if *start != "" {
+		crawlSite(f, *start, *depth, *delay, *sitemap)
+		return
 	}
 
-	ch <- fmt.Sprintf("Response from %s: %s", url, string(body))
-}
-
-func main() {
 	urls := []string{
 		"https://jsonplaceholder.typicode.com/posts/1",
 		"https://jsonplaceholder.typicode.com/posts/2",
 		"https://jsonplaceholder.typicode.com/posts/3",
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(len(urls))
-
-	ch := make(chan string, len(urls))
-
-	for _, url := range urls {
-		go fetchUrl(url, &wg, ch)
+	posts, err := fetch.FetchAll[Post](context.Background(), f, urls)
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, p := range posts {
+		if p.Err == nil {
+			fmt.Printf("%s: #%d %q\n", p.URL, p.Value.ID, p.Value.Title)
+		}
 	}
+}
 
-	wg.Wait()
-	close(ch)
+type Post struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
 
-	for response := range ch {
-		fmt.Println(response)
+func crawlSite(f *fetch.Fetcher, start string, depth int, delay time.Duration, sitemap bool) {
+	c := crawl.New(f, crawl.WithMaxDepth(depth), crawl.WithDelay(delay))
+	report, err := c.Crawl(context.Background(), start)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sitemap {
+		err = report.WriteSitemap(os.Stdout)
+	} else {
+		err = report.WriteJSON(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 }
@@ -1,5 +1,61 @@
 package main
 
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"own3/internal/faults"
+	"own3/internal/processors"
+)
+
+// main greets and shouts every line read from stdin:
+//
+//	printf 'world\ngopher\n' | go run ./cmd/own
+//
+// -fail-rate makes each processor fail that share of its values, the
+// failures are the same for every run with the same -seed.
 func main() {
+	failRate := flag.Float64("fail-rate", 0, "probability of an injected failure per processor and value")
+	seed := flag.Int64("seed", 1, "seed for the injected failures")
+	flag.Parse()
+
+	ctx := context.Background()
+	p := processors.Chain(
+		processors.NewAProcessor(processors.WithFaults(faults.New(*seed, faults.WithFailureRate(*failRate)))),
+		processors.NewBProcessor(processors.WithFaults(faults.New(*seed+1, faults.WithFailureRate(*failRate)))),
+	)
+	p.Start(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for r := range p.Results() {
+			fmt.Println(r.Value)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for err := range p.Errors() {
+			log.Println(err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if err := p.Submit(ctx, processors.ProcessorInput{Value: scanner.Text()}); err != nil {
+			log.Fatal(err)
+		}
+	}
+	p.Close()
+	wg.Wait()
 
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,138 @@
+// Package faults injects failures and latency into code under test in a
+// reproducible way. An Injector decides for every call whether to fail,
+// either by following a fixed script or by drawing from a seeded random
+// number generator, so the same seed always yields the same failures.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjected is the error an Injector returns for an injected failure,
+// unless it's set up with a different one through WithError.
+var ErrInjected = errors.New("injected fault")
+
+// Injector decides whether a call fails and how long it's delayed. It's safe
+// for concurrent use. A nil *Injector never fails and never delays, so
+// callers don't need to check for one.
+type Injector struct {
+	mu       sync.Mutex
+	rng      *rand.Rand
+	failRate float64
+	latency  time.Duration
+	jitter   time.Duration
+	script   string
+	calls    int
+	err      error
+}
+
+// Option configures an Injector.
+type Option func(*Injector)
+
+// WithFailureRate makes calls fail with probability p, 0 <= p <= 1.
+func WithFailureRate(p float64) Option {
+	return func(i *Injector) {
+		i.failRate = p
+	}
+}
+
+// WithLatency delays every call by d plus a random duration of up to jitter.
+func WithLatency(d, jitter time.Duration) Option {
+	return func(i *Injector) {
+		i.latency = d
+		i.jitter = jitter
+	}
+}
+
+// WithScript fixes the outcome of the first len(script) calls: '.' lets a
+// call pass, 'x' makes it fail. "..x" fails the third call. Once the script
+// is used up, the failure rate decides.
+func WithScript(script string) Option {
+	return func(i *Injector) {
+		i.script = script
+	}
+}
+
+// WithError sets the error injected failures wrap. It defaults to
+// ErrInjected.
+func WithError(err error) Option {
+	return func(i *Injector) {
+		i.err = err
+	}
+}
+
+// New returns an Injector whose random decisions are drawn from a generator
+// seeded with seed.
+func New(seed int64, opts ...Option) *Injector {
+	i := &Injector{
+		rng: rand.New(rand.NewSource(seed)),
+		err: ErrInjected,
+	}
+	for _, o := range opts {
+		o(i)
+	}
+	for _, c := range i.script {
+		if c != '.' && c != 'x' {
+			panic(fmt.Sprintf("faults: invalid script %q, only '.' and 'x' are allowed", i.script))
+		}
+	}
+	return i
+}
+
+// Inject waits for the injected latency and then returns either nil or an
+// injected error. If ctx is done while waiting, it returns ctx.Err().
+func (i *Injector) Inject(ctx context.Context) error {
+	if i == nil {
+		return nil
+	}
+	delay, fail, call := i.next()
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if fail {
+		return fmt.Errorf("call %d: %w", call, i.err)
+	}
+	return nil
+}
+
+// Calls returns how many times Inject was called.
+func (i *Injector) Calls() int {
+	if i == nil {
+		return 0
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.calls
+}
+
+// next decides the outcome of the next call. All random numbers are drawn
+// under the lock and in the same order, which keeps a seed's sequence of
+// outcomes stable.
+func (i *Injector) next() (time.Duration, bool, int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.calls++
+
+	delay := i.latency
+	if i.jitter > 0 {
+		delay += time.Duration(i.rng.Int63n(int64(i.jitter)))
+	}
+	var fail bool
+	if i.calls <= len(i.script) {
+		fail = i.script[i.calls-1] == 'x'
+	} else {
+		fail = i.rng.Float64() < i.failRate
+	}
+	return delay, fail, i.calls
+}
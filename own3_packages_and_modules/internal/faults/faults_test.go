@@ -0,0 +1,60 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func outcomes(i *Injector, n int) []bool {
+	var failed []bool
+	for j := 0; j < n; j++ {
+		failed = append(failed, i.Inject(context.Background()) != nil)
+	}
+	return failed
+}
+
+func TestSameSeedSameFailures(t *testing.T) {
+	a := outcomes(New(42, WithFailureRate(0.5)), 50)
+	b := outcomes(New(42, WithFailureRate(0.5)), 50)
+	if !slices.Equal(a, b) {
+		t.Error("expected the same outcomes for the same seed")
+	}
+	if !slices.Contains(a, true) || !slices.Contains(a, false) {
+		t.Error("expected a mix of failures and successes, got", a)
+	}
+}
+
+func TestScript(t *testing.T) {
+	got := outcomes(New(1, WithScript(".x.x")), 6)
+	expected := []bool{false, true, false, true, false, false}
+	if !slices.Equal(expected, got) {
+		t.Error("expected", expected, "got", got)
+	}
+}
+
+func TestCustomError(t *testing.T) {
+	boom := errors.New("boom")
+	err := New(1, WithScript("x"), WithError(boom)).Inject(context.Background())
+	if !errors.Is(err, boom) {
+		t.Error("expected boom, got", err)
+	}
+}
+
+func TestLatencyRespectsContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := New(1, WithLatency(time.Hour, 0)).Inject(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded, got", err)
+	}
+}
+
+func TestNilInjector(t *testing.T) {
+	var i *Injector
+	if err := i.Inject(context.Background()); err != nil {
+		t.Error("expected nil, got", err)
+	}
+}
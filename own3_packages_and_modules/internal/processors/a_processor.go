@@ -1,43 +1,19 @@
 package processors
 
-import (
-	"context"
-	"errors"
-	"math/rand"
-)
-
 var prefix = "hello"
 
+// AProcessor greets every value it's given.
 type AProcessor struct {
-	in     chan string
-	out    chan string
-	errors chan error
+	*worker
 }
 
-func NewAProcessor() *AProcessor {
+func NewAProcessor(opts ...Option) *AProcessor {
 	return &AProcessor{
-		in:     make(chan string),
-		out:    make(chan string),
-		errors: make(chan error),
+		worker: newWorker("a", process, opts),
 	}
 }
 
-func (p *AProcessor) start(ctx context.Context) {
-	go func() {
-		inVal := <-p.in
-		result, err := process(inVal)
-		if err != nil {
-			p.errors <- err
-			return
-		}
-		p.out <- result
-	}()
-}
-
+// process never fails on its own, use WithFaults to make it fail.
 func process(val string) (string, error) {
-	shouldFail := rand.Intn(1)
-	if shouldFail == 1 {
-		return "", errors.New("failing as expected")
-	}
 	return prefix + " " + val, nil
 }
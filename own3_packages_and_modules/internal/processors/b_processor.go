@@ -1 +1,24 @@
 package processors
+
+import (
+	"errors"
+	"strings"
+)
+
+// BProcessor shouts every value it's given, blank values are an error.
+type BProcessor struct {
+	*worker
+}
+
+func NewBProcessor(opts ...Option) *BProcessor {
+	return &BProcessor{
+		worker: newWorker("b", shout, opts),
+	}
+}
+
+func shout(val string) (string, error) {
+	if strings.TrimSpace(val) == "" {
+		return "", errors.New("nothing to shout")
+	}
+	return strings.ToUpper(val) + "!", nil
+}
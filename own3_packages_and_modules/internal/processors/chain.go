@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"context"
+	"sync"
+)
+
+type chain struct {
+	procs  []Processor
+	errors chan error
+}
+
+// Chain connects procs so that the results of each one are submitted to the
+// next. The chain is a Processor itself: input goes to the first processor,
+// results come from the last one and the errors of all of them are merged.
+// Closing the chain closes the first processor, every following one is
+// closed once the one before it has finished.
+func Chain(procs ...Processor) Processor {
+	if len(procs) == 0 {
+		panic("processors: Chain needs at least one processor")
+	}
+	return &chain{
+		procs:  procs,
+		errors: make(chan error),
+	}
+}
+
+func (c *chain) Start(ctx context.Context) {
+	for _, p := range c.procs {
+		p.Start(ctx)
+	}
+	for i := 0; i < len(c.procs)-1; i++ {
+		from, to := c.procs[i], c.procs[i+1]
+		go func() {
+			defer to.Close()
+			for r := range from.Results() {
+				if err := to.Submit(ctx, ProcessorInput{Value: r.Value}); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(c.procs))
+	for _, p := range c.procs {
+		go func() {
+			defer wg.Done()
+			for err := range p.Errors() {
+				c.errors <- err
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(c.errors)
+	}()
+}
+
+func (c *chain) Submit(ctx context.Context, in ProcessorInput) error {
+	return c.procs[0].Submit(ctx, in)
+}
+
+func (c *chain) Results() <-chan ProcessorOutput {
+	return c.procs[len(c.procs)-1].Results()
+}
+
+func (c *chain) Errors() <-chan error {
+	return c.errors
+}
+
+func (c *chain) Close() {
+	c.procs[0].Close()
+}
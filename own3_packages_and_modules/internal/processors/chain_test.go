@@ -0,0 +1,130 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"own3/internal/faults"
+)
+
+// collect reads results and errors of p until both channels are closed.
+func collect(p Processor) ([]string, []error) {
+	var results []string
+	var errs []error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for r := range p.Results() {
+			results = append(results, r.Value)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for err := range p.Errors() {
+			errs = append(errs, err)
+		}
+	}()
+	wg.Wait()
+	return results, errs
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	p := Chain(NewAProcessor(), NewBProcessor())
+	p.Start(ctx)
+
+	go func() {
+		defer p.Close()
+		for _, v := range []string{"world", "gopher"} {
+			if err := p.Submit(ctx, ProcessorInput{Value: v}); err != nil {
+				t.Error("unexpected error:", err)
+			}
+		}
+	}()
+
+	results, errs := collect(p)
+	expected := []string{"HELLO WORLD!", "HELLO GOPHER!"}
+	if !slices.Equal(expected, results) {
+		t.Error("expected", expected, "got", results)
+	}
+	if len(errs) != 0 {
+		t.Error("unexpected errors", errs)
+	}
+}
+
+func TestBProcessorErrors(t *testing.T) {
+	ctx := context.Background()
+	p := NewBProcessor()
+	p.Start(ctx)
+
+	go func() {
+		defer p.Close()
+		p.Submit(ctx, ProcessorInput{Value: "  "})
+	}()
+
+	results, errs := collect(p)
+	if len(results) != 0 || len(errs) != 1 {
+		t.Error("expected a single error, got", results, errs)
+	}
+}
+
+func TestSubmitAfterClose(t *testing.T) {
+	p := NewAProcessor()
+	p.Start(context.Background())
+	p.Close()
+	p.Close()
+	if err := p.Submit(context.Background(), ProcessorInput{Value: "x"}); err != ErrClosed {
+		t.Error("expected ErrClosed, got", err)
+	}
+}
+
+func TestChainWithFaults(t *testing.T) {
+	ctx := context.Background()
+	p := Chain(
+		NewAProcessor(WithFaults(faults.New(1, faults.WithScript(".x.")))),
+		NewBProcessor(WithFaults(faults.New(1, faults.WithScript("x")))),
+	)
+	p.Start(ctx)
+
+	go func() {
+		defer p.Close()
+		for _, v := range []string{"one", "two", "three"} {
+			p.Submit(ctx, ProcessorInput{Value: v})
+		}
+	}()
+
+	// a fails "two", b fails the first value it sees, "hello one"
+	results, errs := collect(p)
+	if !slices.Equal([]string{"HELLO THREE!"}, results) {
+		t.Error("expected only three to make it through, got", results)
+	}
+	if len(errs) != 2 {
+		t.Fatal("expected 2 errors, got", errs)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, faults.ErrInjected) {
+			t.Error("expected an injected fault, got", err)
+		}
+	}
+}
+
+func TestFaultLatencyCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewAProcessor(WithFaults(faults.New(1, faults.WithLatency(time.Hour, 0))))
+	p.Start(ctx)
+	if err := p.Submit(ctx, ProcessorInput{Value: "slow"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	cancel()
+
+	// the worker gives up waiting and closes its channels
+	results, errs := collect(p)
+	if len(results) != 0 || len(errs) != 0 {
+		t.Error("expected nothing after cancellation, got", results, errs)
+	}
+}
@@ -1,9 +1,34 @@
 package processors
 
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrClosed is returned by Submit once the processor was closed.
+	ErrClosed = errors.New("processor closed")
+	// ErrAlreadyStarted is returned by Run if the processor already runs.
+	ErrAlreadyStarted = errors.New("processor already started")
+)
+
 type ProcessorInput struct {
-	value string
+	Value string
 }
 
 type ProcessorOutput struct {
-	value string
+	Value string
+}
+
+// Processor turns inputs into outputs in its own goroutine. Every input
+// submitted results in either an output on Results or an error on Errors, so
+// both channels have to be read. Both are closed once the processor has
+// stopped, which happens after Close was called and all submitted inputs are
+// handled, or when the context passed to Start is done.
+type Processor interface {
+	Start(ctx context.Context)
+	Submit(ctx context.Context, in ProcessorInput) error
+	Results() <-chan ProcessorOutput
+	Errors() <-chan error
+	Close()
 }
@@ -0,0 +1,218 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"own3/internal/faults"
+)
+
+// Option configures a processor.
+type Option func(*worker)
+
+// WithFaults makes the processor consult inj before handling each value,
+// failing or delaying it as inj decides.
+func WithFaults(inj *faults.Injector) Option {
+	return func(w *worker) {
+		w.faults = inj
+	}
+}
+
+// WithBuffer lets up to n submitted values queue up before Submit blocks.
+func WithBuffer(n int) Option {
+	return func(w *worker) {
+		w.buffer = n
+	}
+}
+
+// Summary counts what happened to the values a processor accepted.
+type Summary struct {
+	// Processed values produced a result that was delivered.
+	Processed int
+	// Failed values produced an error that was delivered.
+	Failed int
+	// Dropped values were abandoned because the context was done before
+	// their result or error could be delivered.
+	Dropped int
+}
+
+// worker is the machinery AProcessor and BProcessor share, they only differ
+// in the function applied to each value.
+type worker struct {
+	name   string
+	fn     func(string) (string, error)
+	buffer int
+	in     chan ProcessorInput
+	out    chan ProcessorOutput
+	errors chan error
+	faults *faults.Injector
+
+	// mu guards closed, Submit holds it for reading while sending so that
+	// Close can't close the input channel underneath it and Run knows when
+	// nothing can be added to it anymore
+	mu     sync.RWMutex
+	closed bool
+	// running makes sure only one Run ever owns the channels
+	running atomic.Bool
+	// stopped is closed once Run stops taking values
+	stopped chan struct{}
+
+	processed, failed, dropped atomic.Int64
+}
+
+func newWorker(name string, fn func(string) (string, error), opts []Option) *worker {
+	w := &worker{
+		name:    name,
+		fn:      fn,
+		out:     make(chan ProcessorOutput),
+		errors:  make(chan error),
+		stopped: make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	w.in = make(chan ProcessorInput, w.buffer)
+	return w
+}
+
+// Start runs the processor in a new goroutine, see Run.
+func (w *worker) Start(ctx context.Context) {
+	go w.Run(ctx)
+}
+
+// Run handles submitted values until either the processor is closed and
+// every value submitted before Close has been handled, or ctx is done.
+// In the latter case the value in flight and the ones still queued are
+// dropped and ctx.Err() is returned. Either way Results and Errors are
+// closed exactly once when Run returns. A processor can only be run once,
+// further calls return ErrAlreadyStarted.
+func (w *worker) Run(ctx context.Context) (Summary, error) {
+	if !w.running.CompareAndSwap(false, true) {
+		return Summary{}, ErrAlreadyStarted
+	}
+	defer close(w.errors)
+	defer close(w.out)
+
+	for {
+		// select picks randomly among ready cases, check ctx first so that
+		// queued values aren't handled after cancellation
+		if ctx.Err() != nil {
+			w.drop()
+			return w.Summary(), ctx.Err()
+		}
+		select {
+		case in, ok := <-w.in:
+			if !ok {
+				close(w.stopped)
+				return w.Summary(), nil
+			}
+			w.handle(ctx, in)
+		case <-ctx.Done():
+		}
+	}
+}
+
+// drop stops taking values and counts the queued ones as dropped. Closing
+// stopped wakes Submits waiting for room in the queue, once they let go of
+// mu nothing can be added anymore and the queue holds all that's left.
+func (w *worker) drop() {
+	close(w.stopped)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	for {
+		select {
+		case _, ok := <-w.in:
+			if !ok {
+				return
+			}
+			w.dropped.Add(1)
+		default:
+			return
+		}
+	}
+}
+
+// Summary returns the counts so far. Once Run returned they're final.
+func (w *worker) Summary() Summary {
+	return Summary{
+		Processed: int(w.processed.Load()),
+		Failed:    int(w.failed.Load()),
+		Dropped:   int(w.dropped.Load()),
+	}
+}
+
+func (w *worker) handle(ctx context.Context, in ProcessorInput) {
+	result, err := w.apply(ctx, in.Value)
+	if err != nil && ctx.Err() != nil {
+		// cancelled while working on it
+		w.dropped.Add(1)
+		return
+	}
+	if err != nil {
+		select {
+		case w.errors <- fmt.Errorf("%s: %w", w.name, err):
+			w.failed.Add(1)
+		case <-ctx.Done():
+			w.dropped.Add(1)
+		}
+		return
+	}
+	select {
+	case w.out <- ProcessorOutput{Value: result}:
+		w.processed.Add(1)
+	case <-ctx.Done():
+		w.dropped.Add(1)
+	}
+}
+
+func (w *worker) apply(ctx context.Context, val string) (string, error) {
+	if err := w.faults.Inject(ctx); err != nil {
+		return "", err
+	}
+	return w.fn(val)
+}
+
+// Submit hands in over to the processor. It blocks until the processor
+// accepts it, the processor is closed or stopped, or ctx is done.
+func (w *worker) Submit(ctx context.Context, in ProcessorInput) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	select {
+	case <-w.stopped:
+		return ErrClosed
+	default:
+	}
+	if w.closed {
+		return ErrClosed
+	}
+	select {
+	case w.in <- in:
+		return nil
+	case <-w.stopped:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *worker) Results() <-chan ProcessorOutput {
+	return w.out
+}
+
+func (w *worker) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops the processor from accepting new input, values that were
+// already submitted are still handled. It's safe to call more than once.
+func (w *worker) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.in)
+	}
+}
@@ -0,0 +1,148 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"own3/internal/faults"
+)
+
+func TestRunDrainsQueuedValues(t *testing.T) {
+	ctx := context.Background()
+	p := NewAProcessor(WithBuffer(3))
+	for _, v := range []string{"a", "b", "c"} {
+		if err := p.Submit(ctx, ProcessorInput{Value: v}); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	p.Close()
+
+	done := make(chan Summary)
+	go func() {
+		summary, err := p.Run(ctx)
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+		done <- summary
+	}()
+
+	results, _ := collect(p)
+	expected := []string{"hello a", "hello b", "hello c"}
+	if !slices.Equal(expected, results) {
+		t.Error("expected", expected, "got", results)
+	}
+	if summary := <-done; summary != (Summary{Processed: 3}) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewAProcessor(WithBuffer(2), WithFaults(faults.New(1, faults.WithScript("x"))))
+	done := make(chan struct{})
+	var summary Summary
+	var err error
+	go func() {
+		defer close(done)
+		summary, err = p.Run(ctx)
+	}()
+
+	// nobody reads the error of the first value, so "b" and "c" stay queued
+	for _, v := range []string{"a", "b", "c"} {
+		if err := p.Submit(ctx, ProcessorInput{Value: v}); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	cancel()
+	<-done
+
+	if !errors.Is(err, context.Canceled) {
+		t.Error("expected context.Canceled, got", err)
+	}
+	if summary != (Summary{Dropped: 3}) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if _, ok := <-p.Results(); ok {
+		t.Error("expected results to be closed")
+	}
+	if err := p.Submit(context.Background(), ProcessorInput{Value: "d"}); !errors.Is(err, ErrClosed) {
+		t.Error("expected ErrClosed after Run returned, got", err)
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	p := NewAProcessor()
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := p.Run(context.Background())
+			errs <- err
+		}()
+	}
+	// the Run that got the processor only returns after Close
+	if err := <-errs; !errors.Is(err, ErrAlreadyStarted) {
+		t.Error("expected ErrAlreadyStarted, got", err)
+	}
+	p.Close()
+	if err := <-errs; err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestRunCancelledAccountsForEverySubmit(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		// the first value holds the processor up until it's cancelled, so
+		// the queue fills up and the submitters block
+		entered, release := make(chan struct{}), make(chan struct{})
+		var once sync.Once
+		p := newWorker("test", func(v string) (string, error) {
+			once.Do(func() {
+				close(entered)
+				<-release
+			})
+			return v, nil
+		}, []Option{WithBuffer(64)})
+		done := make(chan Summary)
+		go func() {
+			summary, _ := p.Run(ctx)
+			done <- summary
+		}()
+		go func() {
+			for range p.Results() {
+			}
+		}()
+
+		var accepted atomic.Int64
+		var wg sync.WaitGroup
+		wg.Add(4)
+		for j := 0; j < 4; j++ {
+			go func() {
+				defer wg.Done()
+				for {
+					// not ctx, Submit has to notice the stop by itself
+					if err := p.Submit(context.Background(), ProcessorInput{Value: "x"}); err != nil {
+						return
+					}
+					accepted.Add(1)
+				}
+			}()
+		}
+		<-entered
+		cancel()
+		close(release)
+		summary := <-done
+		wg.Wait()
+
+		if n := int64(summary.Processed + summary.Failed + summary.Dropped); n != accepted.Load() {
+			t.Fatalf("accepted %d values, but the summary %+v accounts for %d", accepted.Load(), summary, n)
+		}
+		if summary.Dropped == 0 {
+			t.Fatalf("expected queued values to be dropped, got %+v", summary)
+		}
+	}
+}